@@ -0,0 +1,280 @@
+package base91
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The armor format wraps base91 data in a self-describing text envelope, in
+// the manner of PEM and OpenPGP ASCII armor, so that it survives being pasted
+// into emails and tickets:
+//
+//	-----BEGIN BASE91 DATA-----
+//	Name: hello.txt
+//
+//	<encoded data in lines of 76 columns>
+//	=<CRC-24 of the data>
+//	-----END BASE91 DATA-----
+//
+// The BEGIN line is followed by any number of "Key: Value" header lines and
+// then a blank line, which is present even if there are no headers. The body
+// follows, and its last line is '=' and the base91 encoding of the CRC-24 of
+// the data, as used by OpenPGP, in 3 big-endian bytes.
+
+const (
+	armorBegin = "-----BEGIN BASE91 DATA-----"
+	armorEnd   = "-----END BASE91 DATA-----"
+	armorCols  = 76
+)
+
+// ErrBadArmor is returned when reading an armored block that does not follow
+// the armor format.
+var ErrBadArmor = errors.New("malformed base91 armor")
+
+// An ArmorBlock is the content of an armored block.
+type ArmorBlock struct {
+	Headers map[string]string // Optional headers.
+	Bytes   []byte            // The decoded data.
+}
+
+// An ArmorWriter writes data as an armored block.
+type ArmorWriter struct {
+	e           encoder
+	w           io.Writer
+	headers     map[string]string
+	crc         uint32
+	buf         []byte
+	wroteHeader bool
+	err         error
+}
+
+// NewArmorWriter returns an ArmorWriter that writes the data written to it to
+// w as an armored block encoded with enc and carrying the given headers, which
+// may be nil. Header keys must not be empty or contain ':' or line breaks, and
+// values must not contain line breaks. Headers are written in sorted order of
+// their keys. The block is complete once Close has been called.
+func NewArmorWriter(enc *Encoding, w io.Writer, headers map[string]string) *ArmorWriter {
+	return &ArmorWriter{
+		e:       encoder{enc: enc.WithWrap(armorCols)},
+		w:       w,
+		headers: headers,
+		crc:     crc24Init,
+	}
+}
+
+// Write encodes p and writes it to the block.
+func (aw *ArmorWriter) Write(p []byte) (int, error) {
+	if aw.err != nil {
+		return 0, aw.err
+	}
+	if aw.err = aw.writeHeader(); aw.err != nil {
+		return 0, aw.err
+	}
+
+	aw.crc = crc24Update(aw.crc, p)
+	const chunkLen = 512
+	if aw.buf == nil {
+		aw.buf = make([]byte, 2*chunkLen)
+	}
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > chunkLen {
+			chunk = chunk[:chunkLen]
+		}
+		k := aw.e.encodeBlock(aw.buf, chunk)
+		if aw.err = aw.e.writeLines(aw.w, aw.buf[:k]); aw.err != nil {
+			return n, aw.err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// Close flushes any buffered data and writes the checksum and END line. It
+// does not close the underlying writer. Close must be called even if no data
+// was written, and Write must not be called after it.
+func (aw *ArmorWriter) Close() error {
+	if aw.err != nil {
+		return aw.err
+	}
+	if aw.err = aw.writeHeader(); aw.err != nil {
+		return aw.err
+	}
+
+	var buf [2]byte
+	n := aw.e.flush(buf[:])
+	if aw.err = aw.e.writeLines(aw.w, buf[:n]); aw.err != nil {
+		return aw.err
+	}
+
+	var trailer []byte
+	if aw.e.col > 0 {
+		trailer = append(trailer, '\n')
+	}
+	trailer = append(trailer, '=')
+	var sum [4]byte
+	n, _ = aw.e.enc.encode91(sum[:], []byte{byte(aw.crc >> 16), byte(aw.crc >> 8), byte(aw.crc)})
+	trailer = append(trailer, sum[:n]...)
+	trailer = append(trailer, "\n"+armorEnd+"\n"...)
+	_, aw.err = aw.w.Write(trailer)
+	if aw.err == nil {
+		aw.err = errors.New("write to closed ArmorWriter")
+		return nil
+	}
+	return aw.err
+}
+
+// writeHeader writes the BEGIN line, headers, and blank line if they have not
+// been written yet.
+func (aw *ArmorWriter) writeHeader() error {
+	if aw.wroteHeader {
+		return nil
+	}
+
+	keys := make([]string, 0, len(aw.headers))
+	for k, v := range aw.headers {
+		if k == "" || strings.ContainsAny(k, ":\r\n") || strings.ContainsAny(v, "\r\n") {
+			return errors.New("invalid armor header " + k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(armorBegin + "\n")
+	for _, k := range keys {
+		b.WriteString(k + ": " + aw.headers[k] + "\n")
+	}
+	b.WriteString("\n")
+	if _, err := io.WriteString(aw.w, b.String()); err != nil {
+		return err
+	}
+	aw.wroteHeader = true
+	return nil
+}
+
+// An ArmorReader reads armored blocks from text that may contain other text
+// around them, such as an email.
+type ArmorReader struct {
+	enc *Encoding
+	r   *bufio.Reader
+}
+
+// NewArmorReader returns an ArmorReader that reads blocks encoded with enc from
+// r. It may read from r beyond the end of the last block it returns.
+func NewArmorReader(enc *Encoding, r io.Reader) *ArmorReader {
+	return &ArmorReader{enc: enc, r: bufio.NewReader(r)}
+}
+
+// Next returns the next armored block, skipping any text before it, or io.EOF
+// if there are no more blocks. If the block is damaged, Next returns
+// ErrBadArmor, ErrChecksum, or a CorruptInputError, and the following call
+// looks for the next block after the damaged one.
+func (ar *ArmorReader) Next() (*ArmorBlock, error) {
+	for {
+		line, err := ar.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if string(bytes.TrimRight(line, " \t")) == armorBegin {
+			break
+		}
+	}
+
+	// Read the whole block first, so that a damaged block is always consumed
+	// up to its END line.
+	var lines [][]byte
+	for {
+		line, err := ar.readLine()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if string(bytes.TrimRight(line, " \t")) == armorEnd {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	block := &ArmorBlock{}
+	blank := -1
+	for i, line := range lines {
+		if len(line) == 0 {
+			blank = i
+			break
+		}
+	}
+	if blank < 0 || blank == len(lines)-1 {
+		return nil, ErrBadArmor
+	}
+	for _, line := range lines[:blank] {
+		i := bytes.Index(line, []byte(": "))
+		if i <= 0 {
+			return nil, ErrBadArmor
+		}
+		if block.Headers == nil {
+			block.Headers = make(map[string]string)
+		}
+		block.Headers[string(line[:i])] = string(line[i+2:])
+	}
+
+	trailer := lines[len(lines)-1]
+	// The checksum is 3 bytes, which encode to at most 4 symbols.
+	if len(trailer) == 0 || len(trailer) > 5 || trailer[0] != '=' {
+		return nil, ErrBadArmor
+	}
+	var sum [4]byte
+	if n, err := ar.enc.decodeUnwrapped(sum[:], trailer[1:]); err != nil || n != 3 {
+		return nil, ErrBadArmor
+	}
+
+	data, err := ar.enc.decodeToNew(bytes.Join(lines[blank+1:len(lines)-1], nil), true)
+	if err != nil {
+		return nil, err
+	}
+	if crc24Update(crc24Init, data) != uint32(sum[0])<<16|uint32(sum[1])<<8|uint32(sum[2]) {
+		return nil, ErrChecksum
+	}
+	block.Bytes = data
+	return block, nil
+}
+
+// readLine returns the next line from the stream without its line break. The
+// last line need not end with a line break, since pasted text often does not.
+func (ar *ArmorReader) readLine() ([]byte, error) {
+	line, err := ar.r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return trimFrameLine(line), nil
+}
+
+const (
+	crc24Init = 0xb704ce
+	crc24Poly = 0x1864cfb
+)
+
+// crc24Update returns the CRC-24 checksum, as defined in RFC 4880, of the data
+// covered by crc followed by p.
+func crc24Update(crc uint32, p []byte) uint32 {
+	for _, c := range p {
+		crc ^= uint32(c) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc & 0xffffff
+}
@@ -0,0 +1,159 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// armored returns data as an armored block with the given headers.
+func armored(data []byte, headers map[string]string) string {
+	var buf bytes.Buffer
+	aw := NewArmorWriter(StdEncoding, &buf, headers)
+	aw.Write(data)
+	aw.Close()
+	return buf.String()
+}
+
+func TestArmorWriter(t *testing.T) {
+	cases := []struct {
+		data    string
+		headers map[string]string
+		want    string
+	}{
+		{"", nil, "-----BEGIN BASE91 DATA-----\n\n=YNKS\n-----END BASE91 DATA-----\n"},
+		{"hello", nil, "-----BEGIN BASE91 DATA-----\n\nTPwJh>A\n=>7TM\n-----END BASE91 DATA-----\n"},
+		{
+			"hello",
+			map[string]string{"Name": "hello.txt", "Comment": "a greeting"},
+			"-----BEGIN BASE91 DATA-----\nComment: a greeting\nName: hello.txt\n\nTPwJh>A\n=>7TM\n-----END BASE91 DATA-----\n",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := armored([]byte(tc.data), tc.headers); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestArmorWriterLines(t *testing.T) {
+	got := armored(benchmarkData(1000), nil)
+	lines := strings.Split(got, "\n")
+	// Skip the BEGIN line and blank line, and the trailer, END line, and the
+	// empty string after the final line break.
+	body := lines[2 : len(lines)-3]
+	for i, line := range body {
+		if i < len(body)-1 && len(line) != 76 {
+			t.Errorf("Expected line %d to be 76 bytes, got %d", i, len(line))
+		}
+		if len(line) == 0 || len(line) > 76 {
+			t.Errorf("Expected line %d to be 1 to 76 bytes, got %d", i, len(line))
+		}
+	}
+}
+
+func TestArmorWriterInvalidHeader(t *testing.T) {
+	for _, h := range []map[string]string{{"": "x"}, {"a:b": "x"}, {"a\nb": "x"}, {"a": "x\ny"}} {
+		aw := NewArmorWriter(StdEncoding, io.Discard, h)
+		if err := aw.Close(); err == nil {
+			t.Errorf("Expected error for headers %q, got nil", h)
+		}
+	}
+}
+
+func TestArmorRoundTrip(t *testing.T) {
+	data := benchmarkData(1000)
+	cases := []struct {
+		n       int
+		headers map[string]string
+	}{
+		{0, nil},
+		{1, nil},
+		{57, map[string]string{"Name": "x"}},
+		{1000, map[string]string{"Name": "data.bin", "Version": "1"}},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			in := "Here is the file:\r\n\r\n" + armored(data[:tc.n], tc.headers) + "Thanks!\n"
+			ar := NewArmorReader(StdEncoding, strings.NewReader(in))
+			block, err := ar.Next()
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(block.Bytes, data[:tc.n]) {
+				t.Errorf("Expected %x, got %x", data[:tc.n], block.Bytes)
+			}
+			if !reflect.DeepEqual(block.Headers, tc.headers) {
+				t.Errorf("Expected headers %v, got %v", tc.headers, block.Headers)
+			}
+			if _, err := ar.Next(); err != io.EOF {
+				t.Errorf("Expected %v, got %v", io.EOF, err)
+			}
+		})
+	}
+}
+
+func TestArmorReaderRecovery(t *testing.T) {
+	damaged := strings.Replace(armored([]byte("hello"), nil), "TPwJh>A", "TPwJh>B", 1)
+	unterminated := strings.TrimSuffix(armored([]byte("x"), nil), "-----END BASE91 DATA-----\n")
+	in := armored([]byte("first"), nil) + damaged + "-----BEGIN BASE91 DATA-----\nbad header\n\n=YNKS\n-----END BASE91 DATA-----\n" +
+		armored([]byte("last"), nil) + unterminated
+
+	ar := NewArmorReader(StdEncoding, strings.NewReader(in))
+	cases := []struct {
+		data string
+		err  error
+	}{
+		{"first", nil},
+		{"", ErrChecksum},
+		{"", ErrBadArmor},
+		{"last", nil},
+		{"", io.ErrUnexpectedEOF},
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			block, err := ar.Next()
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if err == nil && string(block.Bytes) != tc.data {
+				t.Errorf("Expected %q, got %q", tc.data, block.Bytes)
+			}
+		})
+	}
+}
+
+func TestArmorReaderErrors(t *testing.T) {
+	cases := []string{
+		// No blank line.
+		"-----BEGIN BASE91 DATA-----\n=YNKS\n-----END BASE91 DATA-----\n",
+		// No trailer.
+		"-----BEGIN BASE91 DATA-----\n\n-----END BASE91 DATA-----\n",
+		"-----BEGIN BASE91 DATA-----\n\nTPwJh>A\n-----END BASE91 DATA-----\n",
+		// Trailer too long.
+		"-----BEGIN BASE91 DATA-----\n\n=YNKSA\n-----END BASE91 DATA-----\n",
+	}
+
+	for i, in := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := NewArmorReader(StdEncoding, strings.NewReader(in)).Next()
+			if err != ErrBadArmor {
+				t.Errorf("Expected %v, got %v", ErrBadArmor, err)
+			}
+		})
+	}
+}
+
+func TestCRC24(t *testing.T) {
+	// The check value for the CRC-24 of RFC 4880.
+	if got := crc24Update(crc24Init, []byte("123456789")); got != 0x21cf02 {
+		t.Errorf("Expected %x, got %x", 0x21cf02, got)
+	}
+}
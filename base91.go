@@ -35,14 +35,43 @@
 package base91
 
 import (
-	"fmt"
-	"math"
+	"errors"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"strings"
+	"unsafe"
 )
 
 // An Encoding is a base 91 encoding/decoding scheme defined by a 91-character alphabet.
 type Encoding struct {
-	encode    [91]byte
-	decodeMap [256]byte
+	encode          [91]byte
+	decodeMap       [256]byte
+	wrap            int  // Output line length in bytes, or 0 for no wrapping.
+	sep             byte // Byte written between lines: '\n', or the separator set with Group.
+	trailingNewline bool // Whether non-empty output ends with '\n'.
+	strict          bool // Whether decoding rejects bytes that would otherwise be ignored.
+	maxDecodedLen   int  // Limit on data decoded into new buffers, or 0 for no limit.
+
+	// ignore holds the bytes passed to IgnoreChars. invalid is the decode map
+	// entry for bytes that are neither in the alphabet nor ignored: 0xff to
+	// reject them, 0xfe to skip them, or the value of a replacement symbol.
+	ignore  string
+	invalid byte
+
+	name string // Cached result of String.
+
+	// encodePairs, if not nil, maps each value that the encoder emits as a
+	// symbol pair to the two symbols, first symbol in the low byte. See
+	// EncodeTable.
+	encodePairs *[91 * 91]uint16
+
+	// decodePairs, if not nil, maps each pair of input bytes, first byte in the
+	// low byte of the index, to the value of the pair, or to 0xffff if either
+	// byte does not decode to a symbol. It is rebuilt with the decode map when
+	// decodeTable is set. See DecodeTable.
+	decodePairs *[1 << 16]uint16
+	decodeTable bool
 }
 
 // encodeStd is the standard base91 encoding alphabet (that is, the one specified
@@ -63,17 +92,278 @@ func NewEncoding(encoder string) *Encoding {
 		}
 	}
 
+	return newEncoding(encoder)
+}
+
+// NewEncodingStrict is like NewEncoding but returns an error instead of
+// panicking if the alphabet is invalid. It also rejects alphabets that contain
+// the same byte more than once, which NewEncoding does not detect. It is meant
+// for alphabets that come from configuration or other untrusted sources.
+func NewEncodingStrict(alphabet string) (*Encoding, error) {
+	if len(alphabet) != 91 {
+		return nil, errors.New("encoding alphabet is " + strconv.Itoa(len(alphabet)) + " bytes long, not 91")
+	}
+
+	var seen [256]int
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c == '\n' || c == '\r' {
+			return nil, errors.New("encoding alphabet contains newline character at index " + strconv.Itoa(i))
+		}
+		if seen[c] != 0 {
+			return nil, errors.New("encoding alphabet contains " + strconv.QuoteRune(rune(c)) +
+				" at both index " + strconv.Itoa(seen[c]-1) + " and index " + strconv.Itoa(i))
+		}
+		seen[c] = i + 1
+	}
+
+	return newEncoding(alphabet), nil
+}
+
+// printable lists the 95 printable ASCII characters in the order used by
+// NewEncodingOmitting. It is the standard alphabet with the four characters
+// that the standard alphabet omits inserted, so that omitting those four again
+// yields exactly the standard alphabet.
+const printable = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!#$%&'()*+,-./:;<=>?@[\\]^_`{|}~\" "
+
+// NewEncodingOmitting returns a new Encoding whose alphabet consists of the
+// printable ASCII characters (0x20 through 0x7e) other than those in omit.
+// Because there are 95 printable ASCII characters, omit must contain exactly
+// four distinct printable characters. The remaining characters are ordered as
+// in the standard alphabet, so NewEncodingOmitting(" '-\\") is equivalent to
+// StdEncoding.
+func NewEncodingOmitting(omit string) (*Encoding, error) {
+	var omitted [256]bool
+	n := 0
+	for i := 0; i < len(omit); i++ {
+		c := omit[i]
+		if c < 0x20 || c > 0x7e {
+			return nil, errors.New("cannot omit " + strconv.QuoteRune(rune(c)) + ": not a printable ASCII character")
+		}
+		if !omitted[c] {
+			omitted[c] = true
+			n++
+		}
+	}
+	if len(printable)-n != 91 {
+		return nil, errors.New("omitting " + strconv.Itoa(n) + " characters leaves " + strconv.Itoa(len(printable)-n) + ", not 91")
+	}
+
+	alphabet := make([]byte, 0, 91)
+	for i := 0; i < len(printable); i++ {
+		if !omitted[printable[i]] {
+			alphabet = append(alphabet, printable[i])
+		}
+	}
+	return newEncoding(string(alphabet)), nil
+}
+
+// newEncoding returns a new Encoding defined by the given alphabet, which the
+// caller must already have checked.
+func newEncoding(encoder string) *Encoding {
 	e := new(Encoding)
 	copy(e.encode[:], encoder)
+	e.invalid = 0xff
+	e.sep = '\n'
+	e.buildDecodeMap()
+	return e
+}
 
+// buildDecodeMap fills in the decode map from the alphabet and options of e.
+// It must be called whenever either of them changes.
+func (e *Encoding) buildDecodeMap() {
+	invalid := e.invalid
+	if e.strict {
+		invalid = 0xff
+	}
 	for i := 0; i < len(e.decodeMap); i++ {
 		// 0xff indicates that this entry in the decode map is not in the encoding alphabet.
-		e.decodeMap[i] = 0xff
+		e.decodeMap[i] = invalid
 	}
-	for i := 0; i < len(encoder); i++ {
-		e.decodeMap[encoder[i]] = byte(i)
+
+	// 0xfe indicates that this byte is skipped when decoding. Line breaks are
+	// skipped so that wrapped output can be decoded by any Encoding. Strict
+	// decoding accepts only the line breaks that e itself emits.
+	if !e.strict {
+		e.decodeMap['\n'] = 0xfe
+		e.decodeMap['\r'] = 0xfe
+		e.decodeMap[e.sep] = 0xfe
+		for i := 0; i < len(e.ignore); i++ {
+			e.decodeMap[e.ignore[i]] = 0xfe
+		}
+	} else {
+		if e.wrap > 0 {
+			e.decodeMap[e.sep] = 0xfe
+		}
+		if e.trailingNewline {
+			e.decodeMap['\n'] = 0xfe
+		}
+	}
+
+	for i := 0; i < len(e.encode); i++ {
+		e.decodeMap[e.encode[i]] = byte(i)
+	}
+
+	if e.decodeTable {
+		e.buildDecodePairs()
+	}
+	e.name = e.buildName()
+}
+
+// rebuildDecodeMap is like buildDecodeMap for an Encoding copied from another
+// with the same alphabet and only its options changed. The decode pair table
+// depends only on the alphabet, so e keeps sharing it instead of building a
+// new one.
+func (e *Encoding) rebuildDecodeMap() {
+	decodeTable := e.decodeTable
+	e.decodeTable = false
+	e.buildDecodeMap()
+	e.decodeTable = decodeTable
+}
+
+// buildDecodePairs allocates and fills in the decode pair table of e from its
+// decode map. A new table is always allocated, since e may share its old one
+// with the Encoding it was copied from.
+func (e *Encoding) buildDecodePairs() {
+	e.decodePairs = new([1 << 16]uint16)
+	for i := range e.decodePairs {
+		d0, d1 := e.decodeMap[byte(i)], e.decodeMap[byte(i>>8)]
+		if d0 < 91 && d1 < 91 {
+			e.decodePairs[i] = uint16(d0) + uint16(d1)*91
+		} else {
+			e.decodePairs[i] = 0xffff
+		}
 	}
-	return e
+}
+
+// buildEncodePairs allocates and fills in the encode pair table of e.
+func (e *Encoding) buildEncodePairs() {
+	e.encodePairs = new([91 * 91]uint16)
+	for v := uint32(0); v < 91*91; v++ {
+		q, r := divmod91(v)
+		e.encodePairs[v] = uint16(e.encode[r]) | uint16(e.encode[q])<<8
+	}
+}
+
+// isSymbol reports whether c is in the alphabet of enc. Unlike a plain decode
+// map lookup, it is not fooled by a replacement for invalid bytes.
+func (enc *Encoding) isSymbol(c byte) bool {
+	d := enc.decodeMap[c]
+	return d < 91 && enc.encode[d] == c
+}
+
+// WithWrap creates a new encoding identical to enc except that encoded output
+// is broken into lines of at most cols bytes, separated by '\n'. No line break
+// follows the last line. A cols of 0 disables wrapping. Decoding always ignores
+// '\r' and '\n', so wrapped and unwrapped input decode identically.
+func (enc *Encoding) WithWrap(cols int) *Encoding {
+	if cols < 0 {
+		panic("wrap column count is negative")
+	}
+	e := *enc
+	e.wrap = cols
+	e.sep = '\n'
+	e.rebuildDecodeMap()
+	return &e
+}
+
+// Clone returns a new Encoding identical to enc except that each alphabet
+// character that is a key in replacements is replaced by the corresponding
+// value. This is a convenient way to derive an encoding that avoids a few
+// problematic characters, such as replacing '"' for embedding in JSON strings.
+// It returns an error if a key is not in the alphabet, if a value is the group
+// separator of enc or a byte that it ignores, or if the resulting alphabet
+// would be invalid, as checked by NewEncodingStrict.
+func (enc *Encoding) Clone(replacements map[byte]byte) (*Encoding, error) {
+	e := *enc
+	for from, to := range replacements {
+		if !enc.isSymbol(from) {
+			return nil, errors.New("cannot replace " + strconv.QuoteRune(rune(from)) + ": not in the encoding alphabet")
+		}
+		if enc.wrap > 0 && enc.sep != '\n' && to == enc.sep {
+			return nil, errors.New("cannot replace " + strconv.QuoteRune(rune(from)) + " with " + strconv.QuoteRune(rune(to)) + ": it is the group separator")
+		}
+		if strings.IndexByte(enc.ignore, to) >= 0 {
+			return nil, errors.New("cannot replace " + strconv.QuoteRune(rune(from)) + " with " + strconv.QuoteRune(rune(to)) + ": it is an ignored character")
+		}
+		e.encode[enc.decodeMap[from]] = to
+	}
+	if _, err := NewEncodingStrict(string(e.encode[:])); err != nil {
+		return nil, err
+	}
+
+	e.buildDecodeMap()
+	if e.encodePairs != nil {
+		e.buildEncodePairs()
+	}
+	return &e, nil
+}
+
+// Equal reports whether enc and other have the same alphabet and options, and
+// thus produce and accept exactly the same encoded data.
+func (enc *Encoding) Equal(other *Encoding) bool {
+	if enc == nil || other == nil {
+		return enc == other
+	}
+	return enc.encode == other.encode &&
+		enc.decodeMap == other.decodeMap &&
+		enc.wrap == other.wrap &&
+		enc.sep == other.sep &&
+		enc.trailingNewline == other.trailingNewline &&
+		enc.strict == other.strict &&
+		enc.maxDecodedLen == other.maxDecodedLen
+}
+
+// Alphabet returns the 91-byte alphabet that defines enc.
+func (enc *Encoding) Alphabet() string {
+	return string(enc.encode[:])
+}
+
+// String returns a short human-readable name for enc, suitable for logs and
+// error messages. The standard alphabet is named "base91/std"; any other
+// alphabet is named by a hash of its bytes, as in "base91/custom-1a2b3c4d".
+// Non-default options are appended, as in "base91/std,wrap=76".
+func (enc *Encoding) String() string {
+	return enc.name
+}
+
+// buildName returns the name that String reports for enc. It is computed with
+// the decode map, whenever the configuration changes, so that making an error
+// that includes it allocates nothing.
+func (enc *Encoding) buildName() string {
+	name := "base91/" + enc.alphabetID()
+	if enc.wrap > 0 && enc.sep != '\n' {
+		name += ",group=" + strconv.Itoa(enc.wrap) + ",sep=" + strconv.QuoteRune(rune(enc.sep))
+	} else if enc.wrap > 0 {
+		name += ",wrap=" + strconv.Itoa(enc.wrap)
+	}
+	if enc.trailingNewline {
+		name += ",nl"
+	}
+	if enc.maxDecodedLen > 0 {
+		name += ",max=" + strconv.Itoa(enc.maxDecodedLen)
+	}
+	if enc.strict {
+		name += ",strict"
+	} else if enc.invalid == 0xfe {
+		name += ",skip-invalid"
+	} else if enc.invalid < 91 {
+		name += ",replace-invalid=" + strconv.QuoteRune(rune(enc.encode[enc.invalid]))
+	}
+	return name
+}
+
+// alphabetID returns a short identifier for the alphabet of enc: "std" for the
+// standard alphabet, and otherwise "custom-" and the FNV-1a hash of its bytes
+// in 8 hexadecimal digits.
+func (enc *Encoding) alphabetID() string {
+	if string(enc.encode[:]) == encodeStd {
+		return "std"
+	}
+	h := fnv.New32a()
+	h.Write(enc.encode[:])
+	sum := strconv.FormatUint(uint64(h.Sum32()), 16)
+	return "custom-" + strings.Repeat("0", 8-len(sum)) + sum
 }
 
 // StdEncoding is the standard base91 encoding (that is, the one specified
@@ -82,6 +372,10 @@ func NewEncoding(encoder string) *Encoding {
 // hyphen (0x2d), and backslash (0x5c).
 var StdEncoding = NewEncoding(encodeStd)
 
+// ErrShortDst is returned when the destination buffer passed to Encode or
+// Decode is too short to hold the output.
+var ErrShortDst = errors.New("short destination buffer")
+
 /*
  * Encoder
  */
@@ -89,16 +383,48 @@ var StdEncoding = NewEncoding(encodeStd)
 // Encode encodes src using the encoding enc, writing bytes to dst.
 // It returns the number of bytes written, because the exact output size cannot
 // be known before encoding takes place. EncodedLen(len(src)) may be used to
-// determine an upper bound on the output size when allocating a dst slice.
-func (enc *Encoding) Encode(dst, src []byte) int {
-	var queue, numBits uint
+// determine an upper bound on the output size when allocating a dst slice;
+// a dst of at least that length is guaranteed to be large enough. If dst is
+// too short, Encode returns the number of bytes written, which form a prefix
+// of the full encoding, and ErrShortDst.
+func (enc *Encoding) Encode(dst, src []byte) (int, error) {
+	n, err := enc.encode91(dst, src)
+	if enc.wrap > 0 && n > 0 {
+		if max := len(dst) - len(dst)/(enc.wrap+1); n > max {
+			// Not all of the output fits once line breaks are added.
+			n, err = max, ErrShortDst
+		}
+		n = wrapLines(dst, n, enc.wrap, enc.sep)
+	}
+	if enc.trailingNewline && n > 0 && err == nil {
+		if n >= len(dst) {
+			return n, ErrShortDst
+		}
+		dst[n] = '\n'
+		n++
+	}
+	return n, err
+}
 
-	n := 0
-	for i := 0; i < len(src); i++ {
-		queue |= uint(src[i]) << numBits
+// encode91 writes the unwrapped base91 encoding of src to dst and returns the
+// number of bytes written. Only complete symbol pairs are written; if dst is too
+// short it stops before the pair that does not fit and returns ErrShortDst.
+func (enc *Encoding) encode91(dst, src []byte) (int, error) {
+	// The bit queues in the encoder and decoder are uint32 rather than uint so
+	// that the code behaves and performs the same on 32- and 64-bit platforms.
+	// The encoder adds 8 bits to a queue that never holds more than 13 bits
+	// after a pair is taken, so it never holds more than 21 bits. The
+	// word-at-a-time paths in swar.go widen the queue while they run.
+	n, i, queue, numBits := enc.encodeWords(dst, src, 0, 0)
+
+	// d is the part of dst not yet written; slicing it as pairs are written,
+	// rather than indexing dst, lets the compiler drop the bounds checks.
+	d := dst[n:]
+	for _, c := range src[i:] {
+		queue |= uint32(c) << numBits
 		numBits += 8
 		if numBits > 13 {
-			var v uint = queue & 8191
+			var v uint32 = queue & 8191
 
 			if v > 88 {
 				queue >>= 13
@@ -109,112 +435,976 @@ func (enc *Encoding) Encode(dst, src []byte) int {
 				queue >>= 14
 				numBits -= 14
 			}
-			dst[n] = enc.encode[v%91]
-			n++
-			dst[n] = enc.encode[v/91]
-			n++
+			if len(d) < 2 {
+				return len(dst) - len(d), ErrShortDst
+			}
+			p := enc.pair(v)
+			d[0] = byte(p)
+			d[1] = byte(p >> 8)
+			d = d[2:]
 		}
 	}
+	n = len(dst) - len(d)
 
 	if numBits > 0 {
-		dst[n] = enc.encode[queue%91]
-		n++
-
 		if numBits > 7 || queue > 90 {
-			dst[n] = enc.encode[queue/91]
+			if n+2 > len(dst) {
+				return n, ErrShortDst
+			}
+			q, r := divmod91(queue)
+			dst[n] = enc.encode[r]
+			dst[n+1] = enc.encode[q]
+			n += 2
+		} else {
+			if n+1 > len(dst) {
+				return n, ErrShortDst
+			}
+			dst[n] = enc.encode[queue%91]
 			n++
 		}
 	}
 
+	return n, nil
+}
+
+// divmod91 returns v/91 and v%91 for v < 1<<14, the range of values that the
+// encoder emits as symbol pairs. It computes the quotient by multiplying by a
+// fixed-point reciprocal of 91, 11523/2^20, which is exact over that range, and
+// derives the remainder from the quotient, avoiding both a division and a modulo.
+func divmod91(v uint32) (q, r uint32) {
+	q = (v * 11523) >> 20
+	return q, v - q*91
+}
+
+// groupBits returns the number of bits, 13 or 14, that the symbol pair with
+// value v stands for. Which it is depends on the data, so it is computed
+// without a branch that the processor would often mispredict: 88 - v&8191
+// wraps around, setting the top bit, exactly when v&8191 > 88.
+func groupBits(v uint32) uint32 {
+	return 14 - (88-v&8191)>>31
+}
+
+// pair returns the symbol pair for v, which must be less than 91*91, with the
+// first symbol in the low byte.
+func (enc *Encoding) pair(v uint32) uint16 {
+	if pairs := enc.encodePairs; pairs != nil {
+		return pairs[v]
+	}
+	q, r := divmod91(v)
+	return uint16(enc.encode[r]) | uint16(enc.encode[q])<<8
+}
+
+// An encoder holds the state of an encoding in progress so that input can be
+// encoded in pieces.
+type encoder struct {
+	enc       *Encoding
+	queue     uint32
+	numBits   uint32
+	col       int    // Number of bytes written to the current output line.
+	lineBreak []byte // Line break to write, or nil for that of enc.
+	sepBuf    [1]byte
+}
+
+// encodeBlock encodes src to dst, which must be at least 2*len(src) bytes long,
+// and returns the number of bytes written. Bits that do not yet make up a
+// complete symbol pair remain queued for the next call or for flush.
+func (e *encoder) encodeBlock(dst, src []byte) int {
+	n, i, queue, numBits := e.enc.encodeWords(dst, src, e.queue, e.numBits)
+
+	// Since dst has room for 2 bytes per input byte, encodeWords leaves fewer
+	// than 8 bytes, which yield at most 5 pairs. They are encoded into a window
+	// indexed with masked offsets, which needs no bounds checks, and copied out.
+	var out [16]byte
+	k := 0
+	for _, c := range src[i:] {
+		queue |= uint32(c) << numBits
+		numBits += 8
+		if numBits > 13 {
+			var v uint32 = queue & 8191
+
+			if v > 88 {
+				queue >>= 13
+				numBits -= 13
+			} else {
+				// We can take 14 bits.
+				v = queue & 16383
+				queue >>= 14
+				numBits -= 14
+			}
+			p := e.enc.pair(v)
+			out[k&15] = byte(p)
+			out[(k+1)&15] = byte(p >> 8)
+			k += 2
+		}
+	}
+
+	e.queue, e.numBits = queue, numBits
+	return n + copy(dst[n:], out[:k&15])
+}
+
+// flush writes the symbols for any queued bits to dst, which must be at least
+// 2 bytes long, and returns the number of bytes written.
+func (e *encoder) flush(dst []byte) int {
+	n := 0
+	if e.numBits > 0 {
+		q, r := divmod91(e.queue)
+		dst[n] = e.enc.encode[r]
+		n++
+
+		if e.numBits > 7 || e.queue > 90 {
+			dst[n] = e.enc.encode[q]
+			n++
+		}
+	}
+	e.queue, e.numBits = 0, 0
 	return n
 }
 
-// EncodeToString returns the base91 encoding of src.
+// writeLines writes p, which is unwrapped encoded output, to w, inserting line
+// breaks as configured for e.enc. A line break is written only once there is
+// more output to follow it, so no break follows the final line.
+func (e *encoder) writeLines(w io.Writer, p []byte) error {
+	if e.enc.wrap == 0 {
+		e.col += len(p)
+		_, err := w.Write(p)
+		return err
+	}
+
+	for len(p) > 0 {
+		if e.col == e.enc.wrap {
+			if _, err := w.Write(e.eol()); err != nil {
+				return err
+			}
+			e.col = 0
+		}
+		k := e.enc.wrap - e.col
+		if k > len(p) {
+			k = len(p)
+		}
+		if _, err := w.Write(p[:k]); err != nil {
+			return err
+		}
+		e.col += k
+		p = p[k:]
+	}
+	return nil
+}
+
+// finish writes a trailing newline to w if e.enc calls for one and any output
+// has been written.
+func (e *encoder) finish(w io.Writer) error {
+	if e.enc.trailingNewline && e.col > 0 {
+		nl := newline
+		if e.lineBreak != nil {
+			nl = e.lineBreak
+		}
+		_, err := w.Write(nl)
+		return err
+	}
+	return nil
+}
+
+// eol returns the line break or group separator that e writes between lines.
+func (e *encoder) eol() []byte {
+	if e.lineBreak != nil {
+		return e.lineBreak
+	}
+	if e.enc.sep == '\n' {
+		return newline
+	}
+	e.sepBuf[0] = e.enc.sep
+	return e.sepBuf[:]
+}
+
+var newline = []byte{'\n'}
+
+// wrapLines inserts sep after every cols bytes of the n bytes at the start of
+// buf, working backwards so that it can be done in place. It returns the new
+// length. The caller must ensure that buf has room for the separators.
+func wrapLines(buf []byte, n, cols int, sep byte) int {
+	if n == 0 {
+		return 0
+	}
+	breaks := (n - 1) / cols
+	for i := n - 1; breaks > 0; i-- {
+		buf[i+breaks] = buf[i]
+		if i%cols == 0 {
+			breaks--
+			buf[i+breaks] = sep
+		}
+	}
+	return n + (n-1)/cols
+}
+
+// EncodeToString returns the base91 encoding of src. It makes a single
+// allocation of exactly the size of the result.
 func (enc *Encoding) EncodeToString(src []byte) string {
-	buf := make([]byte, enc.EncodedLen(len(src)))
-	n := enc.Encode(buf, src)
-	return string(buf[:n])
+	if size := enc.EncodedLen(len(src)); size <= maxPooledLen {
+		p := getScratch(size)
+		n, _ := enc.Encode(p.b, src)
+		s := string(p.b[:n])
+		p.release()
+		return s
+	}
+
+	// Size the buffer exactly so that it can become the string's backing
+	// array without a copy. Nothing else refers to buf, so this is safe.
+	buf := make([]byte, enc.EncodedLenExact(src))
+	enc.Encode(buf, src)
+	return *(*string)(unsafe.Pointer(&buf))
+}
+
+// EncodeToBuilder appends the base91 encoding of src to b. It is useful for
+// assembling larger text documents without first encoding to a separate string.
+func (enc *Encoding) EncodeToBuilder(b *strings.Builder, src []byte) {
+	const chunkLen = 512
+	var buf [2 * chunkLen]byte
+
+	b.Grow(enc.EncodedLen(len(src)))
+	e := encoder{enc: enc}
+	for len(src) > 0 {
+		chunk := src
+		if len(chunk) > chunkLen {
+			chunk = chunk[:chunkLen]
+		}
+		n := e.encodeBlock(buf[:], chunk)
+		e.writeLines(b, buf[:n])
+		src = src[len(chunk):]
+	}
+	n := e.flush(buf[:])
+	e.writeLines(b, buf[:n])
+	e.finish(b)
+}
+
+// EncodedLenExact returns the exact length in bytes of the base91 encoding of
+// src, including any line breaks that enc adds. Because the encoded length
+// depends on the data and not just on its length, this requires a pass over
+// src, but no output is written. Use it when the exact length must be known
+// before encoding, such as for a length-prefixed field.
+func (enc *Encoding) EncodedLenExact(src []byte) int {
+	pairs, i, queue, numBits := countWords(src, 0, 0)
+
+	n := 2 * pairs
+	for _, c := range src[i:] {
+		queue |= uint32(c) << numBits
+		numBits += 8
+		if numBits > 13 {
+			if queue&8191 > 88 {
+				queue >>= 13
+				numBits -= 13
+			} else {
+				queue >>= 14
+				numBits -= 14
+			}
+			n += 2
+		}
+	}
+
+	if numBits > 0 {
+		n++
+		if numBits > 7 || queue > 90 {
+			n++
+		}
+	}
+
+	return enc.withLineBreaks(n)
 }
 
 // EncodedLen returns an upper bound on the length in bytes of the base91 encoding
 // of an input buffer of length n. The true encoded length may be shorter.
+// If enc wraps its output, the bound includes the line breaks.
+// The bound is tight: it is reached by input consisting entirely of 0xff bytes.
 func (enc *Encoding) EncodedLen(n int) int {
+	return enc.withLineBreaks(maxEncodedLen(n))
+}
+
+// maxEncodedLen returns the tight upper bound on the length of the unwrapped
+// base91 encoding of n bytes.
+func maxEncodedLen(n int) int {
 	// At worst, base91 encodes 13 bits into 16 bits. Even though 14 bits can
 	// sometimes be encoded into 16 bits, assume the worst case to get the upper
 	// bound on encoded length.
-	return int(math.Ceil(float64(n) * 16.0 / 13.0))
+	//
+	// The encoder emits a symbol pair whenever more than 13 bits are queued, so
+	// with 13-bit groups it emits g = ceil(8n/13) - 1 pairs and leaves
+	// t = 8n - 13g bits, where 0 < t <= 13, for the final group. The final group
+	// needs one symbol if t <= 6 (its value is then at most 63, which is less
+	// than 91) and two otherwise. Taking 14 bits for some groups never increases
+	// the total: either the number of pairs stays at g and t shrinks, or there
+	// are fewer pairs, and the final group never needs more than two symbols.
+	if n <= 0 {
+		return 0
+	}
+
+	// Compute ceil(8n/13) as 8q + ceil(8r/13), where n = 13q + r, so that 8n
+	// cannot overflow.
+	q, r := n/13, n%13
+	c := (8*r + 12) / 13
+	g := 8*q + c - 1
+	t := 8*r - 13*(c-1)
+
+	size := 2*g + 2
+	if t <= 6 {
+		size--
+	}
+	return size
+}
+
+// withLineBreaks returns the length of n bytes of unwrapped encoded output
+// once the line breaks that enc adds are included.
+func (enc *Encoding) withLineBreaks(n int) int {
+	if enc.wrap > 0 && n > 0 {
+		n += (n - 1) / enc.wrap
+	}
+	if enc.trailingNewline && n > 0 {
+		n++
+	}
+	return n
 }
 
 /*
  * Decoder
  */
 
+// ErrCorruptInput is the error that CorruptInputError wraps, so that callers can
+// detect invalid input with errors.Is(err, ErrCorruptInput).
+var ErrCorruptInput = errors.New("illegal base91 data")
+
 // A CorruptInputError is returned if invalid base91 data is encountered during decoding.
-type CorruptInputError int64
+type CorruptInputError struct {
+	Offset   int64  // Offset in the input of the invalid byte or symbol group.
+	Byte     byte   // Input byte at Offset, or zero if the input is secret.
+	Encoding string // Name of the Encoding in use, as returned by its String method.
+
+	// The input around Offset, kept in the error itself so that making one
+	// allocates nothing beyond boxing it; see Context.
+	context  [2*contextLen + 1]byte
+	contextN uint8
+}
+
+// contextLen is the number of bytes on each side of the offending byte that
+// CorruptInputError includes in its Context.
+const contextLen = 8
+
+// Context returns up to contextLen input bytes on each side of Offset, for
+// display, or "" if the input is secret.
+func (e CorruptInputError) Context() string {
+	return string(e.context[:e.contextN])
+}
 
 func (e CorruptInputError) Error() string {
-	return fmt.Sprintf("illegal base91 data at input byte %d", int64(e))
+	// The message is built without fmt so that programs that do not otherwise
+	// use fmt, such as those built with TinyGo, need not link it.
+	if e.contextN == 0 {
+		// The input is secret; see DecodeConstantTime.
+		return "illegal base91 data at input byte " + strconv.FormatInt(e.Offset, 10) + " in " + e.Encoding
+	}
+	return "illegal base91 data at input byte " + strconv.FormatInt(e.Offset, 10) +
+		" (0x" + string(hexDigits[e.Byte>>4]) + string(hexDigits[e.Byte&15]) +
+		" " + strconv.QuoteRune(rune(e.Byte)) + ") near " + strconv.Quote(e.Context()) +
+		" in " + e.Encoding
+}
+
+const hexDigits = "0123456789abcdef"
+
+// Unwrap returns ErrCorruptInput.
+func (e CorruptInputError) Unwrap() error {
+	return ErrCorruptInput
+}
+
+// corruptInputError returns a CorruptInputError for the data at src[i].
+func (enc *Encoding) corruptInputError(src []byte, i int) error {
+	return newCorruptInputError(src, i, enc.String())
+}
+
+// newCorruptInputError returns a CorruptInputError for the data at src[i],
+// found while decoding with the encoding named name. Returning it as an error
+// boxes it, which is the one allocation an error costs: Go stores only
+// pointers, and integers below 256, in an interface without allocating, so
+// no error that carries an offset can avoid it, including the int64 that
+// CorruptInputError used to be.
+func newCorruptInputError(src []byte, i int, name string) error {
+	lo, hi := i-contextLen, i+contextLen+1
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(src) {
+		hi = len(src)
+	}
+	e := CorruptInputError{Offset: int64(i), Byte: src[i], Encoding: name}
+	e.contextN = uint8(copy(e.context[:], src[lo:hi]))
+	return e
 }
 
 // Decode decodes src using the encoding enc. It writes at most DecodedLen(len(src))
 // bytes to dst and returns the number of bytes written. If src contains invalid base91
 // data, it will return the number of bytes successfully written and CorruptInputError.
+// If dst is too short to hold the decoded data, it will return the number of bytes
+// successfully written and ErrShortDst. New line characters (\r and \n) and any
+// bytes passed to IgnoreChars are ignored, unless enc is strict.
+//
+// Decode may use all of dst as scratch space, so bytes of dst beyond the number
+// written may be changed.
+//
+// Decoding in place is supported: dst and src may be the same slice, as in
+// Decode(buf, buf). Other overlapping arrangements are not supported.
 func (enc *Encoding) Decode(dst, src []byte) (int, error) {
-	var queue, numBits uint
+	return enc.decode(dst, src, false)
+}
+
+// decodeUnwrapped is like Decode but, if enc is strict, expects src to be laid
+// out as encode91 writes it, with no line breaks, group separators, or
+// trailing newline, whatever the options of enc. The helpers that encode with
+// encode91 decode with it, so that they accept their own output.
+func (enc *Encoding) decodeUnwrapped(dst, src []byte) (int, error) {
+	return enc.decode(dst, src, true)
+}
+
+// decode implements Decode and decodeUnwrapped.
+func (enc *Encoding) decode(dst, src []byte, unwrapped bool) (int, error) {
+	// In-place decoding works because every output byte is written only after
+	// the input bytes it depends on have been read. Each pair of input bytes
+	// yields at most two output bytes, and the first pair yields only one, so
+	// n never exceeds i when dst[n] is written.
+	//
+	// The decoder adds at most 14 bits to a queue that holds at most 7 bits once
+	// its complete bytes are written out, so the queue never holds more than 21
+	// bits. See encode91.
+	var queue, numBits uint32
 	var v int = -1
 
+	// The start offset, value, and preceding leftover bit count of the most
+	// recent group, which strict decoding needs in order to check the last one.
+	var start, lastStart int
+	var lastV, lastNumBits uint32
+
+	if enc.checksLayout() {
+		var i int
+		if unwrapped {
+			i = enc.indexNonSymbol(src)
+		} else {
+			i = enc.indexBadLayout(src)
+		}
+		if i >= 0 {
+			return 0, enc.corruptInputError(src, i)
+		}
+	}
+
+	pairs := enc.decodePairs
 	n := 0
 	for i := 0; i < len(src); i++ {
-		if enc.decodeMap[src[i]] == 0xff {
-			// The character is not in the encoding alphabet.
-			return n, CorruptInputError(i)
+		if v == -1 && i+8 <= len(src) && n+8 <= len(dst) {
+			k, m, q, nb, lv, lnb := enc.decodeWords(dst[n:], src[i:], queue, numBits)
+			if m > 0 {
+				n += k
+				i += m
+				queue, numBits = q, nb
+				lastStart, lastV, lastNumBits = i-2, lv, lnb
+				if i == len(src) {
+					break
+				}
+			}
 		}
-
-		if v == -1 {
-			// Start the next value.
-			v = int(enc.decodeMap[src[i]])
+		if v == -1 && pairs != nil && i+1 < len(src) && pairs[int(src[i])|int(src[i+1])<<8] != 0xffff {
+			// Both bytes are symbols, so take the whole value in one lookup.
+			v = int(pairs[int(src[i])|int(src[i+1])<<8])
+			start = i
+			i++
 		} else {
-			v += int(enc.decodeMap[src[i]]) * 91
-			queue |= uint(v) << numBits
+			d := enc.decodeMap[src[i]]
+			if d == 0xfe {
+				continue
+			}
+			if d == 0xff {
+				// The character is not in the encoding alphabet.
+				return n, enc.corruptInputError(src, i)
+			}
 
-			if (v & 8191) > 88 {
-				numBits += 13
-			} else {
-				numBits += 14
+			if v == -1 {
+				// Start the next value.
+				v = int(d)
+				start = i
+				continue
 			}
+			v += int(d) * 91
+		}
 
+		lastStart, lastV, lastNumBits = start, uint32(v), numBits
+		queue |= uint32(v) << numBits
+
+		numBits += groupBits(uint32(v))
+
+		if n+2 <= len(dst) {
+			// The group completes one or two bytes. Write two and count only
+			// those completed, rather than branching on which it is; a spare
+			// byte is overwritten by the next group.
+			dst[n] = byte(queue)
+			dst[n+1] = byte(queue >> 8)
+			k := numBits / 8
+			n += int(k)
+			queue >>= 8 * k
+			numBits -= 8 * k
+		} else {
 			for ok := true; ok; ok = (numBits > 7) {
+				if n >= len(dst) {
+					return n, ErrShortDst
+				}
 				dst[n] = byte(queue)
 				n++
 
 				queue >>= 8
 				numBits -= 8
 			}
-
-			// Mark this value complete.
-			v = -1
 		}
+
+		// Mark this value complete.
+		v = -1
 	}
 
 	if v != -1 {
-		dst[n] = byte(queue | uint(v)<<numBits)
+		if enc.strict && !canonicalFinalSymbol(uint32(v), numBits) {
+			return n, enc.corruptInputError(src, start)
+		}
+		if n >= len(dst) {
+			return n, ErrShortDst
+		}
+		dst[n] = byte(queue | uint32(v)<<numBits)
 		n++
+	} else if enc.strict && n > 0 {
+		bits := groupBits(lastV)
+		if !canonicalFinalPair(lastV, bits, lastNumBits) {
+			return n, enc.corruptInputError(src, lastStart)
+		}
 	}
 
 	return n, nil
 }
 
 // DecodeString returns the bytes represented by the base91 string s.
+// If enc has a maximum decoded length and s would decode to more than that,
+// DecodeString returns ErrTooLarge without allocating a buffer for the result.
 func (enc *Encoding) DecodeString(s string) ([]byte, error) {
-	dbuf := make([]byte, enc.DecodedLen(len(s)))
-	n, err := enc.Decode(dbuf, []byte(s))
+	return enc.decodeToNew(stringBytes(s), false)
+}
+
+// decodeToNew returns the bytes represented by src in a newly allocated slice,
+// for DecodeString and similar functions, decoding as decode does. src is never
+// written to.
+func (enc *Encoding) decodeToNew(src []byte, unwrapped bool) ([]byte, error) {
+	size, err := enc.decodeBufLen(src)
+	if err != nil {
+		return nil, err
+	}
+	if size <= maxPooledLen {
+		p := getScratch(size)
+		n, err := enc.decode(p.b, src, unwrapped)
+		dbuf := make([]byte, n)
+		copy(dbuf, p.b)
+		p.release()
+		return dbuf, err
+	}
+
+	dbuf := make([]byte, size)
+	n, err := enc.decode(dbuf, src, unwrapped)
 	return dbuf[:n], err
 }
 
+// ErrTooLarge is returned when input would decode to more than the maximum
+// length set with MaxDecodedLen.
+var ErrTooLarge = errors.New("decoded data exceeds maximum length")
+
+// decodeBufLen returns the size of the buffer to allocate for decoding src. If
+// enc has a maximum decoded length, it returns ErrTooLarge if src decodes to
+// more than that. The cheap upper bound from DecodedLen is checked first, so
+// src is scanned only if it is close to the limit.
+func (enc *Encoding) decodeBufLen(src []byte) (int, error) {
+	size := enc.DecodedLen(len(src))
+	if enc.maxDecodedLen == 0 || size <= enc.maxDecodedLen {
+		return size, nil
+	}
+
+	size, err := enc.DecodedLenExact(src)
+	if err != nil {
+		return 0, err
+	}
+	if size > enc.maxDecodedLen {
+		return 0, ErrTooLarge
+	}
+	return size, nil
+}
+
+// DecodeStringInto is like Decode but takes its input as a string. It reads s
+// directly rather than first copying it to a byte slice.
+func (enc *Encoding) DecodeStringInto(dst []byte, s string) (int, error) {
+	return enc.Decode(dst, stringBytes(s))
+}
+
+// stringBytes returns a byte slice that shares its memory with s. The slice
+// must never be written to.
+func stringBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
 // DecodedLen returns the maximum length in bytes of the decoded data
-// corresponding to n bytes of base91-encoded data.
+// corresponding to n bytes of base91-encoded data. The bound is tight for input
+// without line breaks.
 func (enc *Encoding) DecodedLen(n int) int {
 	// At best, base91 encodes 14 bits into 16 bits, so assume that the input is
-	// optimally encoded to get the upper bound on decoded length.
-	return int(math.Ceil(float64(n) * 14.0 / 16.0))
+	// optimally encoded to get the upper bound on decoded length. The decoder
+	// emits a byte for every 8 bits that it has decoded from complete symbol
+	// pairs, and one byte for a trailing lone symbol.
+	if n <= 0 {
+		return 0
+	}
+
+	// Compute floor(14p/8) = floor(7p/4) as 7(p/4) + floor(7(p%4)/4), where
+	// p = n/2, so that 7p cannot overflow.
+	p := n / 2
+	return 7*(p/4) + 7*(p%4)/4 + n%2
+}
+
+// DecodedLenExact returns the exact length in bytes of the data that src
+// decodes to. Unlike DecodedLen, it scans src, accounting for which symbol pairs
+// carry 13 bits and which carry 14, so callers can allocate exactly or check a
+// declared length before decoding. If src contains invalid base91 data, it
+// returns CorruptInputError.
+func (enc *Encoding) DecodedLenExact(src []byte) (int, error) {
+	var numBits uint32
+	var v int = -1
+
+	n := 0
+	for i := 0; i < len(src); i++ {
+		d := enc.decodeMap[src[i]]
+		if d == 0xfe {
+			continue
+		}
+		if d == 0xff {
+			return 0, enc.corruptInputError(src, i)
+		}
+
+		if v == -1 {
+			v = int(d)
+		} else {
+			v += int(d) * 91
+			numBits += groupBits(uint32(v))
+			n += int(numBits / 8)
+			numBits %= 8
+			v = -1
+		}
+	}
+
+	if v != -1 {
+		n++
+	}
+	return n, nil
+}
+
+// IsCanonical reports whether src is exactly what enc would produce when
+// encoding the data that src decodes to. Many byte strings decode to the same
+// data, because a trailing group may carry non-zero padding bits or use two
+// symbols where one suffices, and because line breaks may be added or moved.
+// Only the canonical encoding is accepted, so it is suitable for checking keys
+// that must not alias one another.
+func (enc *Encoding) IsCanonical(src []byte) bool {
+	return enc.indexBadLayout(src) < 0 && enc.indexBadFinalGroup(src) < 0
+}
+
+// A layoutChecker checks, a byte at a time, that encoded input is laid out
+// exactly as enc writes it: every line but the last holds exactly enc.wrap
+// symbols, lines are separated by enc.sep, no line is empty, and a trailing
+// newline is present if and only if enc calls for one. Strict decoding uses it
+// to reject line breaks that the encoder would not have written.
+type layoutChecker struct {
+	enc   *Encoding
+	col   int  // Number of symbols on the current line.
+	broke bool // Whether the last byte was a separator after a full line.
+	ended bool // Whether the trailing newline has been seen.
+}
+
+// next reports whether c, the next byte of input, is one that enc would write
+// at that point.
+func (l *layoutChecker) next(c byte) bool {
+	enc := l.enc
+	switch {
+	case l.ended:
+		return false
+	case enc.isSymbol(c):
+		if enc.wrap > 0 && l.col == enc.wrap {
+			return false
+		}
+		l.col++
+		l.broke = false
+	case enc.wrap > 0 && c == enc.sep && l.col == enc.wrap:
+		l.col = 0
+		l.broke = true
+	case c == '\n' && enc.trailingNewline && l.col > 0:
+		l.ended = true
+	default:
+		return false
+	}
+	return true
+}
+
+// end reports whether the input may end after the bytes passed to next.
+func (l *layoutChecker) end() bool {
+	if l.broke {
+		// Only the trailing newline may follow the last line, and it looks like
+		// a separator if the separator is '\n'.
+		return l.enc.trailingNewline && l.enc.sep == '\n'
+	}
+	if l.enc.trailingNewline {
+		// Empty output has no trailing newline.
+		return l.ended || l.col == 0
+	}
+	return true
+}
+
+// indexBadLayout returns the index of the first byte of src that is not where
+// enc would write it, as judged by a layoutChecker, or -1 if there is none.
+// If src ends where enc would not end it, such as without the trailing newline
+// that enc calls for, the index is that of the last byte.
+func (enc *Encoding) indexBadLayout(src []byte) int {
+	l := layoutChecker{enc: enc}
+	for i, c := range src {
+		if !l.next(c) {
+			return i
+		}
+	}
+	if !l.end() {
+		return len(src) - 1
+	}
+	return -1
+}
+
+// indexNonSymbol returns the index of the first byte of src that is not in the
+// alphabet of enc, or -1 if there is none.
+func (enc *Encoding) indexNonSymbol(src []byte) int {
+	for i, c := range src {
+		if !enc.isSymbol(c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexBadLayoutValid is like indexBadLayout but skips the bytes of src that
+// are invalid in any position.
+func (enc *Encoding) indexBadLayoutValid(src []byte) int {
+	l := layoutChecker{enc: enc}
+	for i, c := range src {
+		if enc.decodeMap[c] == 0xff {
+			continue
+		}
+		if !l.next(c) {
+			return i
+		}
+	}
+	if !l.end() {
+		return len(src) - 1
+	}
+	return -1
+}
+
+// checksLayout reports whether strict decoding with enc must check the layout
+// of its input. Without wrapping or a trailing newline, the decode map already
+// rejects every byte outside the alphabet.
+func (enc *Encoding) checksLayout() bool {
+	return enc.strict && (enc.wrap > 0 || enc.trailingNewline)
+}
+
+// Normalize decodes src and returns the canonical encoding of the result, the
+// one that IsCanonical accepts. Decoding is lenient even if enc is strict: it
+// ignores ASCII white space that is not in the alphabet, as well as the bytes
+// that enc itself ignores, and accepts non-canonical final symbol groups.
+// Bytes that enc would otherwise reject still cause a CorruptInputError, and
+// any maximum decoded length applies as for DecodeString.
+// Normalize is useful for cleaning up encoded values before using them as keys.
+func (enc *Encoding) Normalize(src []byte) ([]byte, error) {
+	lenient := *enc
+	lenient.strict = false
+	for _, c := range []byte(" \t\v\f") {
+		if !enc.isSymbol(c) {
+			lenient.ignore += string(c)
+		}
+	}
+	lenient.rebuildDecodeMap()
+
+	size, err := lenient.decodeBufLen(src)
+	if err != nil {
+		return nil, err
+	}
+	dbuf := make([]byte, size)
+	n, err := lenient.Decode(dbuf, src)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]byte, enc.EncodedLenExact(dbuf[:n]))
+	enc.Encode(dst, dbuf[:n])
+	return dst, nil
+}
+
+// Validate checks src as Decode would but does not stop at the first problem.
+// It returns an error for every byte that Decode would reject and, if enc is
+// strict, for the first line break out of place and for a malformed final
+// symbol group, in order of their offsets. The errors are all
+// CorruptInputError. It returns nil if Decode would accept src. When checking
+// the layout and the final group, rejected bytes are treated as if absent.
+func (enc *Encoding) Validate(src []byte) []error {
+	var errs []error
+	for i := 0; i < len(src); i++ {
+		if enc.decodeMap[src[i]] == 0xff {
+			errs = append(errs, enc.corruptInputError(src, i))
+		}
+	}
+
+	if !enc.strict {
+		return errs
+	}
+	if enc.checksLayout() {
+		if i := enc.indexBadLayoutValid(src); i >= 0 && enc.decodeMap[src[i]] != 0xff {
+			errs = insertError(errs, enc.corruptInputError(src, i))
+		}
+	}
+	if i := enc.indexBadFinalGroup(src); i >= 0 {
+		errs = insertError(errs, enc.corruptInputError(src, i))
+	}
+	return errs
+}
+
+// indexBadFinalGroup returns the index of the first byte of the final symbol
+// group of src if strict decoding would reject that group, or -1 otherwise.
+// Bytes of src that are not symbols are skipped.
+func (enc *Encoding) indexBadFinalGroup(src []byte) int {
+	var numBits, lastV, lastNumBits uint32
+	var v int = -1
+	var start, lastStart int
+	pairs := 0
+	for i, c := range src {
+		if !enc.isSymbol(c) {
+			continue
+		}
+		d := enc.decodeMap[c]
+		if v == -1 {
+			v = int(d)
+			start = i
+		} else {
+			v += int(d) * 91
+			lastStart, lastV, lastNumBits = start, uint32(v), numBits
+			numBits += groupBits(uint32(v))
+			numBits %= 8
+			pairs++
+			v = -1
+		}
+	}
+
+	if v != -1 {
+		if !canonicalFinalSymbol(uint32(v), numBits) {
+			return start
+		}
+	} else if pairs > 0 {
+		if !canonicalFinalPair(lastV, groupBits(lastV), lastNumBits) {
+			return lastStart
+		}
+	}
+	return -1
+}
+
+// insertError inserts err, which must be a CorruptInputError, into errs, which
+// is sorted by offset, keeping it sorted.
+func insertError(errs []error, err error) []error {
+	off := err.(CorruptInputError).Offset
+	i := len(errs)
+	for i > 0 && errs[i-1].(CorruptInputError).Offset > off {
+		i--
+	}
+	errs = append(errs, nil)
+	copy(errs[i+1:], errs[i:])
+	errs[i] = err
+	return errs
+}
+
+// canonicalFinalPair reports whether a symbol pair with value v that decodes
+// to numBits bits is what the encoder would emit as the last pair of its output,
+// given that r bits (fewer than 8) were left over from the preceding pairs.
+func canonicalFinalPair(v, numBits, r uint32) bool {
+	// The data ends on a byte boundary, so t of the pair's bits are data and the
+	// rest are padding.
+	t := (r+numBits)/8*8 - r
+	if t < numBits && v>>t != 0 {
+		// The padding bits are not zero.
+		return false
+	}
+	// The encoder uses a pair for a final group of t bits only if t > 7 or its
+	// value does not fit in one symbol.
+	return t > 7 || v > 90
+}
+
+// canonicalFinalSymbol reports whether a lone symbol with value v is what the
+// encoder would emit at the end of its output, given that r bits (fewer than 8)
+// were left over from the preceding pairs.
+func canonicalFinalSymbol(v, r uint32) bool {
+	// The symbol carries the 8 - r bits that complete the last byte. The encoder
+	// uses a single symbol only for 7 or fewer bits, and the bits above those
+	// must be zero.
+	return r > 0 && v < 1<<(8-r)
+}
+
+// Valid reports whether Decode would accept src. Unless enc is strict, that
+// is whether src consists only of bytes in the encoding alphabet and bytes that
+// enc ignores: '\r', '\n', the group separator, and any IgnoreChars. With
+// SkipInvalid or ReplaceInvalid, decoding accepts any byte, so every src is
+// valid. If enc is strict, which overrides those options, src must instead be
+// laid out exactly as enc would lay it out and end with a final symbol group
+// that enc would produce. Unlike Decode, Valid does not need a destination
+// buffer.
+func (enc *Encoding) Valid(src []byte) bool {
+	return enc.IndexInvalid(src) < 0
+}
+
+// IndexInvalid returns the index of the first byte in src that is neither in
+// the encoding alphabet nor ignored by enc, as described for Valid, or -1 if
+// there is no such byte, as is always the case with SkipInvalid or
+// ReplaceInvalid.
+// If the result i is not -1, src[:i] is the longest prefix of src that Decode
+// accepts, which is useful for extracting base91 data embedded in other text.
+//
+// If enc is strict, IndexInvalid instead returns the offset of the
+// CorruptInputError that Decode would return, or -1 if Decode would accept src.
+// That may be the offset of a byte out of place or of the start of a malformed
+// final symbol group, so the prefix property does not hold.
+func (enc *Encoding) IndexInvalid(src []byte) int {
+	if enc.strict {
+		return enc.indexInvalidStrict(src)
+	}
+	for i := skipValidWords(&enc.decodeMap, src); i < len(src); i++ {
+		if enc.decodeMap[src[i]] == 0xff {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexInvalidStrict implements IndexInvalid for a strict encoding, checking
+// src in the order that Decode does.
+func (enc *Encoding) indexInvalidStrict(src []byte) int {
+	if enc.checksLayout() {
+		if i := enc.indexBadLayout(src); i >= 0 {
+			return i
+		}
+	}
+	for i := skipValidWords(&enc.decodeMap, src); i < len(src); i++ {
+		if enc.decodeMap[src[i]] == 0xff {
+			return i
+		}
+	}
+	return enc.indexBadFinalGroup(src)
+}
+
+// ValidString is like Valid but takes a string.
+func (enc *Encoding) ValidString(s string) bool {
+	return enc.IndexInvalid(stringBytes(s)) < 0
 }
@@ -35,7 +35,10 @@ package base91
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -75,7 +78,10 @@ func TestEncode(t *testing.T) {
 		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
 			dst := make([]byte, StdEncoding.EncodedLen(len(p.decoded)))
 
-			n := StdEncoding.Encode(dst, []byte(p.decoded))
+			n, err := StdEncoding.Encode(dst, []byte(p.decoded))
+			if err != nil {
+				t.Fatalf("Got encoding error: %v", err)
+			}
 			got := dst[:n]
 			if !bytes.Equal(got, []byte(p.encoded)) {
 				t.Errorf("Expected %v, got %v", []byte(p.encoded), got)
@@ -84,6 +90,36 @@ func TestEncode(t *testing.T) {
 	}
 }
 
+func TestEncodeShortDst(t *testing.T) {
+	src := []byte("foobar") // Encodes to "dr/2s)uC".
+	cases := []struct {
+		enc     *Encoding
+		dstLen  int
+		encoded string
+	}{
+		{StdEncoding, 0, ""},
+		{StdEncoding, 1, ""},
+		{StdEncoding, 5, "dr/2"},
+		{StdEncoding, 7, "dr/2s)"},
+		{StdEncoding.WithWrap(3), 7, "dr/\n2s)"},
+		{StdEncoding.WithWrap(3), 9, "dr/\n2s)\nu"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			dst := make([]byte, tc.dstLen)
+
+			n, err := tc.enc.Encode(dst, src)
+			if err != ErrShortDst {
+				t.Errorf("Expected ErrShortDst, got %v", err)
+			}
+			if got := string(dst[:n]); got != tc.encoded {
+				t.Errorf("Expected %q, got %q", tc.encoded, got)
+			}
+		})
+	}
+}
+
 func TestEncodeToString(t *testing.T) {
 	for i, p := range pairs {
 		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
@@ -113,6 +149,23 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeInPlace(t *testing.T) {
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			for _, enc := range []*Encoding{StdEncoding, StdEncoding.WithWrap(5)} {
+				buf := []byte(enc.EncodeToString([]byte(p.decoded)))
+
+				n, err := enc.Decode(buf, buf)
+				if err != nil {
+					t.Errorf("Got decoding error: %v", err)
+				} else if got := buf[:n]; !bytes.Equal(got, []byte(p.decoded)) {
+					t.Errorf("Expected %v, got %v", []byte(p.decoded), got)
+				}
+			}
+		})
+	}
+}
+
 func TestDecodeInvalidData(t *testing.T) {
 	cases := []string{
 		"~_1H=x_t{ |$AjJX(nMFdjL~:?1b3HgM", // Spaces are not in the standard encoding alphabet.
@@ -131,6 +184,73 @@ func TestDecodeInvalidData(t *testing.T) {
 	}
 }
 
+func TestDecodeShortDst(t *testing.T) {
+	cases := []struct {
+		dstLen  int
+		decoded string
+	}{
+		{0, ""},
+		{1, "f"},
+		{3, "foo"},
+		{5, "fooba"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			dst := make([]byte, tc.dstLen)
+
+			n, err := StdEncoding.Decode(dst, []byte("dr/2s)uC"))
+			if err != ErrShortDst {
+				t.Errorf("Expected ErrShortDst, got %v", err)
+			}
+			if got := string(dst[:n]); got != tc.decoded {
+				t.Errorf("Expected %q, got %q", tc.decoded, got)
+			}
+		})
+	}
+}
+
+func TestCorruptInputError(t *testing.T) {
+	src := "dr/2s)uCdr/2s)uC-dr/2s)uCdr/2s)uC"
+	_, err := StdEncoding.DecodeString(src)
+
+	e, ok := err.(CorruptInputError)
+	if !ok || e.Offset != 16 || e.Byte != '-' || e.Context() != "dr/2s)uC-dr/2s)uC" || e.Encoding != "base91/std" {
+		t.Fatalf("Expected error at offset 16 with byte '-' and context %q in base91/std, got %#v", "dr/2s)uC-dr/2s)uC", err)
+	}
+
+	msg := `illegal base91 data at input byte 16 (0x2d '-') near "dr/2s)uC-dr/2s)uC" in base91/std`
+	if got := err.Error(); got != msg {
+		t.Errorf("Expected %q, got %q", msg, got)
+	}
+
+	if !errors.Is(err, ErrCorruptInput) {
+		t.Errorf("Expected error to match ErrCorruptInput")
+	}
+	if errors.Is(ErrShortDst, ErrCorruptInput) {
+		t.Errorf("Expected ErrShortDst not to match ErrCorruptInput")
+	}
+
+	_, err = StdEncoding.WithWrap(76).DecodeString("\x00AB")
+	e, ok = err.(CorruptInputError)
+	if !ok || e.Offset != 0 || e.Byte != 0 || e.Context() != "\x00AB" || e.Encoding != "base91/std,wrap=76" {
+		t.Errorf("Expected error at offset 0 with byte 0 and context %q in base91/std,wrap=76, got %#v", "\x00AB", err)
+	}
+}
+
+func TestCorruptInputErrorAllocs(t *testing.T) {
+	// Building an error only boxes it: the context is kept in the error itself
+	// and formatted only by Context and Error, and the name of the encoding is
+	// not formatted each time. The boxing cannot be avoided; see
+	// newCorruptInputError.
+	enc := NewEncodingWithOptions(encodeStd, Wrap(76), Strict(), MaxDecodedLen(1000))
+	src := []byte("dr/2s)uC-dr/2s)uC")
+	dst := make([]byte, enc.DecodedLen(len(src)))
+	if n := testing.AllocsPerRun(100, func() { enc.Decode(dst, src) }); n != 1 {
+		t.Errorf("Expected 1 allocation, got %v", n)
+	}
+}
+
 func TestDecodeString(t *testing.T) {
 	for i, p := range pairs {
 		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
@@ -145,3 +265,719 @@ func TestDecodeString(t *testing.T) {
 		})
 	}
 }
+
+func TestWithWrap(t *testing.T) {
+	cases := []struct {
+		cols    int
+		decoded string
+		encoded string
+	}{
+		{0, "foobar", "dr/2s)uC"},
+		{3, "", ""},
+		{3, "foobar", "dr/\n2s)\nuC"},
+		{4, "foobar", "dr/2\ns)uC"},
+		{8, "foobar", "dr/2s)uC"},
+		{1, "foo", "d\nr\n.\nJ"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			enc := StdEncoding.WithWrap(tc.cols)
+
+			got := enc.EncodeToString([]byte(tc.decoded))
+			if got != tc.encoded {
+				t.Errorf("Expected %q, got %q", tc.encoded, got)
+			}
+
+			if n := enc.EncodedLen(len(tc.decoded)); n < len(got) {
+				t.Errorf("EncodedLen(%d) = %d is less than actual length %d", len(tc.decoded), n, len(got))
+			}
+
+			decoded, err := StdEncoding.DecodeString(got)
+			if err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if string(decoded) != tc.decoded {
+				t.Errorf("Expected %q, got %q", tc.decoded, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeIgnoresNewlines(t *testing.T) {
+	got, err := StdEncoding.DecodeString("\r\ndr/\r\n2s)\nuC\n")
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if string(got) != "foobar" {
+		t.Errorf("Expected %q, got %q", "foobar", got)
+	}
+}
+
+func TestDecodeStringInto(t *testing.T) {
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			dst := make([]byte, StdEncoding.DecodedLen(len(p.encoded)))
+
+			n, err := StdEncoding.DecodeStringInto(dst, p.encoded)
+			if err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if got := dst[:n]; !bytes.Equal(got, []byte(p.decoded)) {
+				t.Errorf("Expected %v, got %v", []byte(p.decoded), got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	cases := []struct {
+		s    string
+		want bool
+	}{
+		{"", true},
+		{"dr/2s)uC", true},
+		{"dr/\n2s)\r\nuC", true},
+		{"dr/2 s)uC", false},
+		{"-", false},
+		{"\\", false},
+		{"'", false},
+		{"\x00", false},
+		{"\xff", false},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := StdEncoding.Valid([]byte(tc.s)); got != tc.want {
+				t.Errorf("Valid: expected %v, got %v", tc.want, got)
+			}
+			if got := StdEncoding.ValidString(tc.s); got != tc.want {
+				t.Errorf("ValidString: expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIndexInvalid(t *testing.T) {
+	cases := []struct {
+		s    string
+		want int
+	}{
+		{"", -1},
+		{"dr/2s)uC", -1},
+		{"dr/\n2s)uC", -1},
+		{"dr/2s)uC - next field", 8},
+		{"'quoted'", 0},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := StdEncoding.IndexInvalid([]byte(tc.s)); got != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIndexInvalidStrict(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	wrapped := NewEncodingWithOptions(encodeStd, Strict(), Wrap(4), TrailingNewline())
+	cases := []struct {
+		enc  *Encoding
+		s    string
+		want int
+	}{
+		{strict, "", -1},
+		{strict, "dr/2s)uC", -1},
+		{strict, "~", 0},
+		{strict, "~~", 0},
+		{strict, "B", 0},
+		{strict, "dr.:", 2},
+		{strict, "dr/2s)uC - next field", 8},
+		{wrapped, "dr/2\ns)uC\n", -1},
+		{wrapped, "dr/2\n\ns)uC\n", 5},
+		{wrapped, "dr/2\ns)uC", 8},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := tc.enc.IndexInvalid([]byte(tc.s)); got != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, got)
+			}
+			if got := tc.enc.ValidString(tc.s); got != (tc.want < 0) {
+				t.Errorf("Expected ValidString %v, got %v", tc.want < 0, got)
+			}
+			_, err := tc.enc.DecodeString(tc.s)
+			if e, ok := err.(CorruptInputError); tc.want >= 0 && (!ok || e.Offset != int64(tc.want)) {
+				t.Errorf("Expected Decode to fail at offset %d, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	if got := StdEncoding.Alphabet(); got != encodeStd {
+		t.Errorf("Expected %q, got %q", encodeStd, got)
+	}
+
+	custom := "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!#$%&()*+,./:;<=>?@[]^_`{|}~'"
+	if got := NewEncoding(custom).WithWrap(76).Alphabet(); got != custom {
+		t.Errorf("Expected %q, got %q", custom, got)
+	}
+}
+
+func TestString(t *testing.T) {
+	custom := NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!#$%&()*+,./:;<=>?@[]^_`{|}~'")
+	cases := []struct {
+		enc  *Encoding
+		want string
+	}{
+		{StdEncoding, "base91/std"},
+		{StdEncoding.WithWrap(76), "base91/std,wrap=76"},
+		{custom, "base91/custom-c3546cef"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := tc.enc.String(); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewEncodingStrict(t *testing.T) {
+	enc, err := NewEncodingStrict(encodeStd)
+	if err != nil {
+		t.Fatalf("Got error for standard alphabet: %v", err)
+	}
+	if got := enc.EncodeToString([]byte("foobar")); got != "dr/2s)uC" {
+		t.Errorf("Expected %q, got %q", "dr/2s)uC", got)
+	}
+
+	cases := []string{
+		"",
+		encodeStd[:90],
+		encodeStd + "-",
+		encodeStd[:90] + "\n",
+		encodeStd[:89] + "\r\"",
+		encodeStd[:90] + "A", // Duplicate 'A'.
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			enc, err := NewEncodingStrict(tc)
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if enc != nil {
+				t.Errorf("Expected nil Encoding, got %v", enc)
+			}
+		})
+	}
+}
+
+func TestNewEncodingOmitting(t *testing.T) {
+	enc, err := NewEncodingOmitting(" '-\\")
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if got := enc.Alphabet(); got != encodeStd {
+		t.Errorf("Expected %q, got %q", encodeStd, got)
+	}
+
+	enc, err = NewEncodingOmitting("\"'`\\")
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	alphabet := enc.Alphabet()
+	for _, c := range "\"'`\\" {
+		if strings.ContainsRune(alphabet, c) {
+			t.Errorf("Alphabet %q contains omitted character %q", alphabet, c)
+		}
+	}
+	if _, err := NewEncodingStrict(alphabet); err != nil {
+		t.Errorf("Derived alphabet is invalid: %v", err)
+	}
+
+	cases := []string{
+		"",
+		"\"'`",
+		"\"'`\\-",
+		"\"'`\t",
+		"\"''`",
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if _, err := NewEncodingOmitting(tc); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	enc, err := StdEncoding.WithWrap(4).Clone(map[byte]byte{'"': '\''})
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+
+	want := encodeStd[:90] + "'"
+	if got := enc.Alphabet(); got != want {
+		t.Errorf("Expected alphabet %q, got %q", want, got)
+	}
+	if enc.wrap != 4 {
+		t.Errorf("Expected wrap 4, got %d", enc.wrap)
+	}
+	if StdEncoding.Alphabet() != encodeStd {
+		t.Errorf("Clone modified the original Encoding")
+	}
+
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			encoded := enc.EncodeToString([]byte(p.decoded))
+			if strings.Contains(encoded, "\"") {
+				t.Errorf("Encoded output %q contains replaced character", encoded)
+			}
+			got, err := enc.DecodeString(encoded)
+			if err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if !bytes.Equal(got, []byte(p.decoded)) {
+				t.Errorf("Expected %v, got %v", []byte(p.decoded), got)
+			}
+		})
+	}
+
+	swapped, err := StdEncoding.Clone(map[byte]byte{'A': 'B', 'B': 'A'})
+	if err != nil {
+		t.Fatalf("Got error for swap: %v", err)
+	}
+	if got := swapped.Alphabet(); got != "BA"+encodeStd[2:] {
+		t.Errorf("Expected alphabet %q, got %q", "BA"+encodeStd[2:], got)
+	}
+	if got, err := swapped.DecodeString(swapped.EncodeToString([]byte("foobar"))); err != nil || string(got) != "foobar" {
+		t.Errorf("Expected %q, got %q (error: %v)", "foobar", got, err)
+	}
+
+	grouped := NewEncodingWithOptions(encodeStd, Group(4, '-'))
+	ignoring := NewEncodingWithOptions(encodeStd, IgnoreChars(" -"))
+	errCases := []struct {
+		enc *Encoding
+		r   map[byte]byte
+	}{
+		{StdEncoding, map[byte]byte{'-': '_'}},
+		{StdEncoding, map[byte]byte{'"': 'A'}},
+		{StdEncoding, map[byte]byte{'"': '\n'}},
+		{grouped, map[byte]byte{'"': '-'}},
+		{ignoring, map[byte]byte{'"': '-'}},
+		{ignoring, map[byte]byte{'A': '"', '"': ' '}},
+	}
+	for _, tc := range errCases {
+		if _, err := tc.enc.Clone(tc.r); err == nil {
+			t.Errorf("%v: expected error for replacements %q, got nil", tc.enc, tc.r)
+		}
+	}
+	if _, err := StdEncoding.Clone(map[byte]byte{'"': '-'}); err != nil {
+		t.Errorf("Got error for a replacement that is not a separator: %v", err)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	quoteless, _ := StdEncoding.Clone(map[byte]byte{'"': '\''})
+	cases := []struct {
+		a, b *Encoding
+		want bool
+	}{
+		{StdEncoding, StdEncoding, true},
+		{StdEncoding, NewEncoding(encodeStd), true},
+		{StdEncoding, StdEncoding.WithWrap(0), true},
+		{StdEncoding.WithWrap(76), StdEncoding.WithWrap(76), true},
+		{StdEncoding, StdEncoding.WithWrap(76), false},
+		{StdEncoding, quoteless, false},
+		{StdEncoding, NewEncodingWithOptions(encodeStd, Strict()), false},
+		{StdEncoding, NewEncodingWithOptions(encodeStd, IgnoreChars(" ")), false},
+		{StdEncoding, nil, false},
+		{nil, nil, true},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := tc.a.Equal(tc.b); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEncodeToBuilder(t *testing.T) {
+	encs := []*Encoding{
+		StdEncoding,
+		StdEncoding.WithWrap(3),
+		NewEncodingWithOptions(encodeStd, Wrap(5), TrailingNewline()),
+	}
+	long := bytes.Repeat([]byte(pairs[0].decoded), 20)
+
+	for i, enc := range encs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			for _, src := range [][]byte{nil, []byte("foobar"), long} {
+				var b strings.Builder
+				b.WriteString("prefix:")
+				enc.EncodeToBuilder(&b, src)
+
+				want := "prefix:" + enc.EncodeToString(src)
+				if got := b.String(); got != want {
+					t.Errorf("Expected %q, got %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodedLenExact(t *testing.T) {
+	encs := []*Encoding{
+		StdEncoding,
+		StdEncoding.WithWrap(3),
+		NewEncodingWithOptions(encodeStd, Wrap(5), TrailingNewline()),
+	}
+
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			for _, enc := range encs {
+				dst := make([]byte, enc.EncodedLen(len(p.decoded)))
+				n, err := enc.Encode(dst, []byte(p.decoded))
+				if err != nil {
+					t.Fatalf("Got encoding error: %v", err)
+				}
+				if got := enc.EncodedLenExact([]byte(p.decoded)); got != n {
+					t.Errorf("%v: expected %d, got %d", enc, n, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodedLenTight(t *testing.T) {
+	for n := 0; n < 200; n++ {
+		// 0xff bytes always take the 13-bit path, so they produce the longest
+		// possible encoding.
+		worst := bytes.Repeat([]byte{0xff}, n)
+		if got, want := StdEncoding.EncodedLen(n), StdEncoding.EncodedLenExact(worst); got != want {
+			t.Errorf("EncodedLen(%d): expected %d, got %d", n, want, got)
+		}
+
+		for _, b := range []byte{0x00, 0x55, 0xaa} {
+			src := bytes.Repeat([]byte{b}, n)
+			if got, exact := StdEncoding.EncodedLen(n), StdEncoding.EncodedLenExact(src); got < exact {
+				t.Errorf("EncodedLen(%d) = %d is less than the length %d of the encoding of %x", n, got, exact, src)
+			}
+		}
+	}
+}
+
+func TestDecodedLenTight(t *testing.T) {
+	for n := 0; n < 200; n++ {
+		// 'A' has value 0, so every pair of them decodes to 14 bits, which is the
+		// most possible.
+		src := bytes.Repeat([]byte{'A'}, n)
+		dst := make([]byte, n)
+		got, err := StdEncoding.Decode(dst, src)
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if want := StdEncoding.DecodedLen(n); got != want {
+			t.Errorf("DecodedLen(%d): expected %d, got %d", n, want, got)
+		}
+	}
+}
+
+func TestDecodedLenExact(t *testing.T) {
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			for _, enc := range []*Encoding{StdEncoding, StdEncoding.WithWrap(3)} {
+				got, err := StdEncoding.DecodedLenExact([]byte(enc.EncodeToString([]byte(p.decoded))))
+				if err != nil {
+					t.Errorf("Got error: %v", err)
+				} else if got != len(p.decoded) {
+					t.Errorf("Expected %d, got %d", len(p.decoded), got)
+				}
+			}
+		})
+	}
+
+	_, err := StdEncoding.DecodedLenExact([]byte("dr/2-s)uC"))
+	if e, ok := err.(CorruptInputError); !ok || e.Offset != 4 {
+		t.Errorf("Expected CorruptInputError at offset 4, got %v", err)
+	}
+}
+
+func TestIsCanonical(t *testing.T) {
+	encs := []*Encoding{
+		StdEncoding,
+		StdEncoding.WithWrap(3),
+		NewEncodingWithOptions(encodeStd, Wrap(4), TrailingNewline()),
+	}
+
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			for _, enc := range encs {
+				encoded := enc.EncodeToString([]byte(p.decoded))
+				if !enc.IsCanonical([]byte(encoded)) {
+					t.Errorf("%v: expected %q to be canonical", enc, encoded)
+				}
+			}
+		})
+	}
+
+	cases := []struct {
+		enc *Encoding
+		s   string
+	}{
+		{StdEncoding, "dr/2s)uC\n"},
+		{StdEncoding, "dr/2\ns)uC"},
+		{StdEncoding, "dr/2s)u-"},
+		{StdEncoding.WithWrap(3), "dr/2s)uC"},
+		{StdEncoding.WithWrap(3), "dr/\n2s)\nuC\n"},
+		{StdEncoding.WithWrap(3), "dr/\n\n2s)\nuC"},
+		{StdEncoding.WithWrap(4), "dr/2\r\ns)uC"},
+		{NewEncodingWithOptions(encodeStd, TrailingNewline()), "dr/2s)uC"},
+		{StdEncoding, "A"}, // A lone symbol must complete a partially decoded byte.
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("noncanonical_%d", i), func(t *testing.T) {
+			if tc.enc.IsCanonical([]byte(tc.s)) {
+				t.Errorf("%v: expected %q not to be canonical", tc.enc, tc.s)
+			}
+		})
+	}
+}
+
+// TestIsCanonicalExhaustive checks IsCanonical against decoding and re-encoding
+// for every string of up to three symbols.
+func TestIsCanonicalExhaustive(t *testing.T) {
+	buf := make([]byte, 3)
+	var check func(n int)
+	check = func(n int) {
+		src := buf[:n]
+		decoded, err := StdEncoding.DecodeString(string(src))
+		if err != nil {
+			t.Fatalf("Got decoding error for %q: %v", src, err)
+		}
+		want := StdEncoding.EncodeToString(decoded) == string(src)
+		if got := StdEncoding.IsCanonical(src); got != want {
+			t.Errorf("IsCanonical(%q): expected %v, got %v", src, want, got)
+		}
+		if n == len(buf) {
+			return
+		}
+		for i := 0; i < 91; i++ {
+			buf[n] = encodeStd[i]
+			check(n + 1)
+		}
+	}
+	check(0)
+}
+
+func TestValidate(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	cases := []struct {
+		enc     *Encoding
+		input   string
+		offsets []int64
+	}{
+		{StdEncoding, "dr/2s)uC", nil},
+		{StdEncoding, "dr/2\ns)uC", nil},
+		{StdEncoding, "dr/2 s)-uC'", []int64{4, 7, 10}},
+		{strict, "dr/2\ns)uC", []int64{4}},
+		{strict, "dr.:", []int64{2}},
+		{strict, "dr.- :", []int64{2, 3, 4}}, // The final pair starts before the rejected bytes.
+		{strict, "dr.:-", []int64{2, 4}},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			errs := tc.enc.Validate([]byte(tc.input))
+			if len(errs) != len(tc.offsets) {
+				t.Fatalf("Expected %d errors, got %v", len(tc.offsets), errs)
+			}
+			for j, err := range errs {
+				if e, ok := err.(CorruptInputError); !ok || e.Offset != tc.offsets[j] {
+					t.Errorf("Expected CorruptInputError at offset %d, got %v", tc.offsets[j], err)
+				}
+			}
+
+			_, err := tc.enc.DecodeString(tc.input)
+			if (err == nil) != (len(errs) == 0) {
+				t.Errorf("Validate and Decode disagree: Validate returned %v, Decode returned %v", errs, err)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict(), Wrap(4), TrailingNewline())
+	cases := []struct {
+		enc   *Encoding
+		input string
+		want  string
+	}{
+		{StdEncoding, "", ""},
+		{StdEncoding, "dr/2s)uC", "dr/2s)uC"},
+		{StdEncoding, " dr/2\ts)uC\r\n", "dr/2s)uC"},
+		{StdEncoding, "dr.:", "drZH"},
+		{StdEncoding, "dr\"", "drC"},
+		{StdEncoding.WithWrap(3), "dr/2s)uC", "dr/\n2s)\nuC"},
+		{strict, "dr/2 s)uC", "dr/2\ns)uC\n"},
+		{strict, "dr.:", "drZH\n"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			got, err := tc.enc.Normalize([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("Got error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			if !tc.enc.IsCanonical(got) {
+				t.Errorf("Expected normalized output %q to be canonical", got)
+			}
+		})
+	}
+
+	if _, err := StdEncoding.Normalize([]byte("dr/2-s)uC")); !errors.Is(err, ErrCorruptInput) {
+		t.Errorf("Expected ErrCorruptInput, got %v", err)
+	}
+}
+
+// TestRoundTripRandom checks that random data of many lengths survives a round
+// trip. Run it with GOARCH=386 as well to check 32-bit platforms.
+func TestRoundTripRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(91))
+	for n := 0; n < 1024; n++ {
+		src := make([]byte, n)
+		r.Read(src)
+
+		encoded := StdEncoding.EncodeToString(src)
+		got, err := StdEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatalf("Got decoding error for %x: %v", src, err)
+		}
+		if !bytes.Equal(got, src) {
+			t.Fatalf("Expected %x, got %x", src, got)
+		}
+	}
+}
+
+func benchmarkData(n int) []byte {
+	src := make([]byte, n)
+	rand.New(rand.NewSource(91)).Read(src)
+	return src
+}
+
+func BenchmarkEncode(b *testing.B) {
+	src := benchmarkData(64 << 10)
+	dst := make([]byte, StdEncoding.EncodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Encode(dst, src)
+	}
+}
+
+func BenchmarkEncodeTable(b *testing.B) {
+	enc := NewEncodingWithOptions(encodeStd, EncodeTable())
+	src := benchmarkData(64 << 10)
+	dst := make([]byte, enc.EncodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		enc.Encode(dst, src)
+	}
+}
+
+func BenchmarkEncodeToString(b *testing.B) {
+	src := benchmarkData(64)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.EncodeToString(src)
+	}
+}
+
+func BenchmarkEncodeToStringLarge(b *testing.B) {
+	src := benchmarkData(1 << 20)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.EncodeToString(src)
+	}
+}
+
+func BenchmarkEncodedLenExact(b *testing.B) {
+	src := benchmarkData(64 << 10)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.EncodedLenExact(src)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	src := []byte(StdEncoding.EncodeToString(benchmarkData(64 << 10)))
+	dst := make([]byte, StdEncoding.DecodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Decode(dst, src)
+	}
+}
+
+func BenchmarkDecodeTable(b *testing.B) {
+	enc := NewEncodingWithOptions(encodeStd, DecodeTable())
+	src := []byte(enc.EncodeToString(benchmarkData(64 << 10)))
+	dst := make([]byte, enc.DecodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		enc.Decode(dst, src)
+	}
+}
+
+func BenchmarkDecodeWrapped(b *testing.B) {
+	enc := StdEncoding.WithWrap(76)
+	src := []byte(enc.EncodeToString(benchmarkData(64 << 10)))
+	dst := make([]byte, enc.DecodedLen(len(src)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		enc.Decode(dst, src)
+	}
+}
+
+func BenchmarkValid(b *testing.B) {
+	src := []byte(StdEncoding.EncodeToString(benchmarkData(64 << 10)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Valid(src)
+	}
+}
+
+func BenchmarkDecodeString(b *testing.B) {
+	src := StdEncoding.EncodeToString(benchmarkData(64))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.DecodeString(src)
+	}
+}
+
+func TestDivmod91(t *testing.T) {
+	for v := uint32(0); v < 1<<14; v++ {
+		if q, r := divmod91(v); q != v/91 || r != v%91 {
+			t.Fatalf("divmod91(%d) = %d, %d; expected %d, %d", v, q, r, v/91, v%91)
+		}
+	}
+}
+
+func TestGroupBits(t *testing.T) {
+	for v := uint32(0); v < 91*91; v++ {
+		want := uint32(14)
+		if v&8191 > 88 {
+			want = 13
+		}
+		if got := groupBits(v); got != want {
+			t.Fatalf("groupBits(%d) = %d; expected %d", v, got, want)
+		}
+	}
+}
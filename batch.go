@@ -0,0 +1,62 @@
+package base91
+
+// EncodeBatch sets dst[i] to the base91 encoding of src[i] for each i. It is
+// meant for encoding many small messages, such as the values in a column of a
+// database table: rather than allocating a buffer per message, it writes every
+// encoding to one buffer, allocated once, and the elements of dst are slices
+// of it. Each has no spare capacity, so appending to one does not overwrite
+// the next. If dst is shorter than src, EncodeBatch returns ErrShortDst and
+// encodes nothing.
+func (enc *Encoding) EncodeBatch(dst, src [][]byte) error {
+	if len(dst) < len(src) {
+		return ErrShortDst
+	}
+
+	size := 0
+	for _, s := range src {
+		size += enc.EncodedLen(len(s))
+	}
+
+	buf := make([]byte, size)
+	off := 0
+	for i, s := range src {
+		n, _ := enc.Encode(buf[off:], s)
+		dst[i] = buf[off : off+n : off+n]
+		off += n
+	}
+	return nil
+}
+
+// DecodeBatch sets dst[i] to the bytes represented by src[i] for each i,
+// writing all of them to a single buffer in the same way as EncodeBatch. It
+// returns the number of messages decoded. If src[n] cannot be decoded,
+// DecodeBatch stops and returns n and the error for that message, and dst[:n]
+// holds the messages before it. The limit set with MaxDecodedLen applies to
+// each message separately. If dst is shorter than src, DecodeBatch returns 0
+// and ErrShortDst and decodes nothing.
+func (enc *Encoding) DecodeBatch(dst, src [][]byte) (int, error) {
+	if len(dst) < len(src) {
+		return 0, ErrShortDst
+	}
+
+	size := 0
+	for i, s := range src {
+		n, err := enc.decodeBufLen(s)
+		if err != nil {
+			return i, err
+		}
+		size += n
+	}
+
+	buf := make([]byte, size)
+	off := 0
+	for i, s := range src {
+		n, err := enc.Decode(buf[off:], s)
+		if err != nil {
+			return i, err
+		}
+		dst[i] = buf[off : off+n : off+n]
+		off += n
+	}
+	return len(src), nil
+}
@@ -0,0 +1,73 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeBatch(t *testing.T) {
+	data := benchmarkData(100)
+	src := [][]byte{nil, data[:1], data[:6], []byte("foobar"), data}
+	for _, enc := range []*Encoding{StdEncoding, StdEncoding.WithWrap(4)} {
+		dst := make([][]byte, len(src))
+		if err := enc.EncodeBatch(dst, src); err != nil {
+			t.Fatalf("Got encoding error: %v", err)
+		}
+		for i := range src {
+			if want := enc.EncodeToString(src[i]); string(dst[i]) != want {
+				t.Errorf("%v: message %d: expected %q, got %q", enc, i, want, dst[i])
+			}
+			if cap(dst[i]) != len(dst[i]) {
+				t.Errorf("%v: message %d: expected no spare capacity, got len %d and cap %d", enc, i, len(dst[i]), cap(dst[i]))
+			}
+		}
+
+		decoded := make([][]byte, len(src))
+		if n, err := enc.DecodeBatch(decoded, dst); err != nil || n != len(src) {
+			t.Fatalf("%v: DecodeBatch returned %d, %v", enc, n, err)
+		}
+		for i := range src {
+			if !bytes.Equal(decoded[i], src[i]) {
+				t.Errorf("%v: message %d: expected %x, got %x", enc, i, src[i], decoded[i])
+			}
+		}
+	}
+
+	if err := StdEncoding.EncodeBatch(make([][]byte, 1), src); err != ErrShortDst {
+		t.Errorf("Expected %v, got %v", ErrShortDst, err)
+	}
+}
+
+func TestDecodeBatchError(t *testing.T) {
+	src := [][]byte{[]byte("dr/2s)uC"), []byte("dr.J"), []byte("dr-J"), []byte("dr/2s)uC")}
+	dst := make([][]byte, len(src))
+	n, err := StdEncoding.DecodeBatch(dst, src)
+	if n != 2 || !errors.Is(err, ErrCorruptInput) {
+		t.Fatalf("Expected 2 and %v, got %d and %v", ErrCorruptInput, n, err)
+	}
+	if string(dst[0]) != "foobar" || string(dst[1]) != "foo" {
+		t.Errorf("Expected messages before the error to be decoded, got %q", dst[:n])
+	}
+
+	limited := NewEncodingWithOptions(encodeStd, MaxDecodedLen(4))
+	if n, err := limited.DecodeBatch(dst, src); n != 0 || err != ErrTooLarge {
+		t.Errorf("Expected 0 and %v, got %d and %v", ErrTooLarge, n, err)
+	}
+	if n, err := StdEncoding.DecodeBatch(dst[:1], src); n != 0 || err != ErrShortDst {
+		t.Errorf("Expected 0 and %v, got %d and %v", ErrShortDst, n, err)
+	}
+}
+
+func BenchmarkEncodeBatch(b *testing.B) {
+	data := benchmarkData(64 * 100)
+	src := make([][]byte, 100)
+	for i := range src {
+		src[i] = data[64*i : 64*(i+1)]
+	}
+	dst := make([][]byte, len(src))
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.EncodeBatch(dst, src)
+	}
+}
@@ -0,0 +1,56 @@
+package base91
+
+import "math/big"
+
+// EncodeBigInt returns the encoding of x as a number in base 91, as described
+// for NumericEncoding, preceded by '-' if x is negative. Zero is encoded as the
+// first symbol of the alphabet, and other values never start with it, so each
+// integer has exactly one encoding. EncodeBigInt panics if x is negative and
+// '-' is in the alphabet, since the sign could not be told apart from a digit.
+func (enc *NumericEncoding) EncodeBigInt(x *big.Int) string {
+	if x.Sign() == 0 {
+		return string(enc.enc.encode[0])
+	}
+	s := enc.EncodeToString(x.Bytes())
+	if x.Sign() < 0 {
+		if enc.enc.isSymbol('-') {
+			panic("cannot encode negative integer: '-' is in the encoding alphabet")
+		}
+		s = "-" + s
+	}
+	return s
+}
+
+// DecodeBigInt returns the integer encoded in s by EncodeBigInt. It returns a
+// CorruptInputError if s contains anything other than symbols of the alphabet
+// after an optional '-', or if it is not the encoding of its value that
+// EncodeBigInt produces, such as one with superfluous leading zero digits. If s
+// has no digits, DecodeBigInt returns ErrWrongLength.
+func (enc *NumericEncoding) DecodeBigInt(s string) (*big.Int, error) {
+	digits := s
+	if len(s) > 0 && s[0] == '-' && !enc.enc.isSymbol('-') {
+		digits = s[1:]
+	}
+	if digits == "" {
+		return nil, ErrWrongLength
+	}
+	start := len(s) - len(digits)
+	if digits[0] == enc.enc.encode[0] && (len(digits) > 1 || start > 0) {
+		// A leading zero digit is only valid for the number zero, which has no
+		// sign.
+		return nil, newCorruptInputError(stringBytes(s), start, enc.String())
+	}
+
+	b, err := enc.DecodeString(digits)
+	if err != nil {
+		if e, ok := err.(CorruptInputError); ok && start > 0 {
+			return nil, newCorruptInputError(stringBytes(s), int(e.Offset)+start, enc.String())
+		}
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(b)
+	if start > 0 {
+		x.Neg(x)
+	}
+	return x, nil
+}
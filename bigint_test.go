@@ -0,0 +1,88 @@
+package base91
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestBigInt(t *testing.T) {
+	enc := StdEncoding.Numeric()
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890123456789012345678901234567890", 10)
+	cases := []struct {
+		x       *big.Int
+		encoded string
+	}{
+		{big.NewInt(0), "A"},
+		{big.NewInt(1), "B"},
+		{big.NewInt(-1), "-B"},
+		{big.NewInt(91), "BA"},
+		{big.NewInt(-256), "-C:"},
+		{huge, ""},
+		{new(big.Int).Neg(huge), ""},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			s := enc.EncodeBigInt(tc.x)
+			if tc.encoded != "" && s != tc.encoded {
+				t.Errorf("Expected %q, got %q", tc.encoded, s)
+			}
+			x, err := enc.DecodeBigInt(s)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if x.Cmp(tc.x) != 0 {
+				t.Errorf("Expected %v, got %v", tc.x, x)
+			}
+		})
+	}
+}
+
+func TestDecodeBigIntInvalid(t *testing.T) {
+	enc := StdEncoding.Numeric()
+	cases := []struct {
+		s      string
+		offset int64 // Offset of a CorruptInputError, or -1 for ErrWrongLength.
+	}{
+		{"", -1},
+		{"-", -1},
+		{"AB", 0},
+		{"-A", 1},
+		{"-B-", 2},
+		{"B C", 1},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := enc.DecodeBigInt(tc.s)
+			if tc.offset < 0 {
+				if err != ErrWrongLength {
+					t.Errorf("Expected ErrWrongLength, got %v", err)
+				}
+				return
+			}
+			var e CorruptInputError
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected CorruptInputError, got %v", err)
+			}
+			if e.Offset != tc.offset {
+				t.Errorf("Expected offset %d, got %d", tc.offset, e.Offset)
+			}
+		})
+	}
+}
+
+func TestEncodeBigIntNegativeWithHyphen(t *testing.T) {
+	enc, err := NewEncodingOmitting(" '\\\"")
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic, got none")
+		}
+	}()
+	enc.Numeric().EncodeBigInt(big.NewInt(-1))
+}
@@ -0,0 +1,82 @@
+package base91
+
+import (
+	"hash"
+	"hash/crc32"
+)
+
+// EncodeWithChecksum returns the base91 encoding of src followed by its CRC-32
+// (IEEE) checksum in 4 big-endian bytes. The checksum is encoded along with
+// the data, as if it were part of it, so the result is an ordinary base91
+// string, about 5 bytes longer than the encoding of src alone. It lets a
+// receiver detect truncated or damaged data with DecodeWithChecksum without a
+// separate digest. It is the same as EncodeWithHash with crc32.NewIEEE().
+func (enc *Encoding) EncodeWithChecksum(src []byte) string {
+	return enc.EncodeWithHash(src, crc32.NewIEEE(), 0)
+}
+
+// DecodeWithChecksum returns the bytes represented by s, which was produced by
+// EncodeWithChecksum, after checking them against the checksum at the end. It
+// returns ErrChecksum if they do not match, or if s is too short to hold a
+// checksum.
+func (enc *Encoding) DecodeWithChecksum(s string) ([]byte, error) {
+	return enc.DecodeWithHash(s, crc32.NewIEEE(), 0)
+}
+
+// EncodeWithHash is like EncodeWithChecksum but uses h to compute the checksum,
+// so that any checksum or hash function can be used, such as CRC-32C from
+// hash/crc32, a 64-bit hash from hash/crc64 or hash/fnv, or a cryptographic
+// hash. The checksum is the first size bytes of the sum computed by h, or all
+// of it if size is 0; for the hash.Hash32 and hash.Hash64 implementations in
+// the standard library, the sum is big-endian. Truncating a cryptographic hash
+// keeps the output short while still detecting accidental damage. h is reset
+// before use, and size must not exceed h.Size().
+func (enc *Encoding) EncodeWithHash(src []byte, h hash.Hash, size int) string {
+	size = checksumSize(h, size)
+	h.Reset()
+	h.Write(src)
+
+	buf := make([]byte, len(src), len(src)+h.Size())
+	copy(buf, src)
+	buf = h.Sum(buf)[:len(src)+size]
+	return enc.EncodeToString(buf)
+}
+
+// DecodeWithHash returns the bytes represented by s, which was produced by
+// EncodeWithHash with the same hash function and size, after checking them
+// against the checksum at the end. It returns ErrChecksum if they do not
+// match, or if s is too short to hold a checksum.
+func (enc *Encoding) DecodeWithHash(s string, h hash.Hash, size int) ([]byte, error) {
+	size = checksumSize(h, size)
+	buf, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	n := len(buf) - size
+	if n < 0 {
+		return nil, ErrChecksum
+	}
+
+	h.Reset()
+	h.Write(buf[:n])
+	var scratch [64]byte
+	sum := h.Sum(scratch[:0])
+	// A mismatch is not secret, so there is no need for a constant-time
+	// comparison.
+	if string(sum[:size]) != string(buf[n:]) {
+		return nil, ErrChecksum
+	}
+	return buf[:n:n], nil
+}
+
+// checksumSize returns the number of bytes of the sum computed by h to use as
+// a checksum, given the size passed to EncodeWithHash or DecodeWithHash.
+func checksumSize(h hash.Hash, size int) int {
+	if size < 0 || size > h.Size() {
+		panic("checksum size out of range")
+	}
+	if size == 0 {
+		return h.Size()
+	}
+	return size
+}
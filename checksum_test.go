@@ -0,0 +1,113 @@
+package base91
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
+	"testing"
+)
+
+func TestEncodeWithChecksum(t *testing.T) {
+	data := benchmarkData(1000)
+	for i, n := range []int{0, 1, 4, 5, 100, 1000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			encoded := StdEncoding.EncodeWithChecksum(data[:n])
+
+			// The output is an ordinary encoding of the data and its checksum.
+			raw, err := StdEncoding.DecodeString(encoded)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if len(raw) != n+4 || !bytes.Equal(raw[:n], data[:n]) {
+				t.Errorf("Expected %x followed by a checksum, got %x", data[:n], raw)
+			}
+
+			decoded, err := StdEncoding.DecodeWithChecksum(encoded)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data[:n]) {
+				t.Errorf("Expected %x, got %x", data[:n], decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeWithChecksumErrors(t *testing.T) {
+	encoded := StdEncoding.EncodeWithChecksum([]byte("hello, world"))
+	cases := []struct {
+		in  string
+		err error
+	}{
+		{"", ErrChecksum},
+		{StdEncoding.EncodeToString([]byte("abc")), ErrChecksum},
+		{encoded[:len(encoded)-2], ErrChecksum},
+		{"A" + encoded[1:], ErrChecksum},
+		{StdEncoding.EncodeToString([]byte("hello, world")), ErrChecksum},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if _, err := StdEncoding.DecodeWithChecksum(tc.in); err != tc.err {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	if _, err := StdEncoding.DecodeWithChecksum("A-A"); err == nil {
+		t.Error("Expected error for invalid input, got nil")
+	}
+}
+
+func TestEncodeWithHash(t *testing.T) {
+	data := benchmarkData(100)
+	cases := []struct {
+		h    hash.Hash
+		size int
+	}{
+		{crc32.NewIEEE(), 0},
+		{crc32.New(crc32.MakeTable(crc32.Castagnoli)), 0},
+		{crc64.New(crc64.MakeTable(crc64.ECMA)), 0},
+		{fnv.New64a(), 0},
+		{sha256.New(), 0},
+		{sha256.New(), 8},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			encoded := StdEncoding.EncodeWithHash(data, tc.h, tc.size)
+
+			size := tc.size
+			if size == 0 {
+				size = tc.h.Size()
+			}
+			tc.h.Reset()
+			tc.h.Write(data)
+			want := tc.h.Sum(append([]byte(nil), data...))[:len(data)+size]
+			if got, _ := StdEncoding.DecodeString(encoded); !bytes.Equal(got, want) {
+				t.Errorf("Expected %x, got %x", want, got)
+			}
+
+			decoded, err := StdEncoding.DecodeWithHash(encoded, tc.h, tc.size)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("Expected %x, got %x", data, decoded)
+			}
+
+			if _, err := StdEncoding.DecodeWithHash(encoded[:len(encoded)-1], tc.h, tc.size); err != ErrChecksum {
+				t.Errorf("Expected %v, got %v", ErrChecksum, err)
+			}
+		})
+	}
+
+	// The default is CRC-32.
+	if got, want := StdEncoding.EncodeWithChecksum(data), StdEncoding.EncodeWithHash(data, crc32.NewIEEE(), 4); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
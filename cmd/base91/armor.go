@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mtraver/base91"
+)
+
+// runArmor runs the armor subcommand and returns its exit code.
+func runArmor(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("base91 armor", "[-H 'Key: Value'] [-o output] [-q] [--encoding name | --alphabet chars] [file ...]", stderr)
+	headers := headerFlag{}
+	var common commonFlags
+	fs.Var(headers, "H", "add the header `'Key: Value'` to the block (may be repeated)")
+	fs.Var(headers, "header", "add the header `'Key: Value'` to the block (may be repeated)")
+	common.register(fs)
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return usageExitCode(err)
+	}
+	enc, err := common.baseEncoding(fs)
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitUsage
+	}
+
+	err = common.convert(files, stdin, stdout, stderr, func(w io.Writer, r io.Reader) error {
+		return encode(base91.NewArmorWriter(enc, w, headers), r)
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runDearmor runs the dearmor subcommand and returns its exit code.
+func runDearmor(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("base91 dearmor", "[-o output] [-q] [--encoding name | --alphabet chars] [file ...]", stderr)
+	var common commonFlags
+	common.register(fs)
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return usageExitCode(err)
+	}
+	enc, err := common.baseEncoding(fs)
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitUsage
+	}
+
+	err = common.convert(files, stdin, stdout, stderr, func(w io.Writer, r io.Reader) error {
+		return dearmor(w, r, enc)
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// dearmor writes the data of each armored block read from r to w. It fails if
+// any block is damaged or if there are none.
+func dearmor(w io.Writer, r io.Reader, enc *base91.Encoding) error {
+	ar := base91.NewArmorReader(enc, r)
+	for n := 0; ; n++ {
+		block, err := ar.Next()
+		if err == io.EOF {
+			if n == 0 {
+				return errors.New("no armored block found")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(block.Bytes); err != nil {
+			return err
+		}
+	}
+}
+
+// headerFlag is a flag.Value that collects armor headers given as "Key: Value".
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(s string) error {
+	i := strings.Index(s, ": ")
+	if i <= 0 || strings.ContainsAny(s[:i], ":\r\n") || strings.ContainsAny(s[i+2:], "\r\n") {
+		return errors.New("header must have the form 'Key: Value'")
+	}
+	h[s[:i]] = s[i+2:]
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+func TestArmor(t *testing.T) {
+	data := strings.Repeat("binary\x00blob\xff", 20)
+	code, out, errOut := runCommand([]string{"armor", "-H", "Name: blob.bin", "--header", "Comment: for review"}, data)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+
+	block, err := base91.NewArmorReader(base91.StdEncoding, strings.NewReader(out)).Next()
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if string(block.Bytes) != data {
+		t.Errorf("Expected %q, got %q", data, block.Bytes)
+	}
+	want := map[string]string{"Name": "blob.bin", "Comment": "for review"}
+	if fmt.Sprint(block.Headers) != fmt.Sprint(want) {
+		t.Errorf("Expected headers %v, got %v", want, block.Headers)
+	}
+
+	// The block is found among other text, and its data written out.
+	code, got, errOut := runCommand([]string{"dearmor"}, "Here it is:\n\n"+out+"\nThanks.\n")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+	if got != data {
+		t.Errorf("Expected %q, got %q", data, got)
+	}
+}
+
+func TestArmorEncoding(t *testing.T) {
+	data := `"quoted"`
+	_, out, _ := runCommand([]string{"armor", "--encoding", "json"}, data)
+	if strings.Contains(out[strings.Index(out, "\n\n"):], `"`) {
+		t.Errorf("Expected no '\"' in JSONEncoding block, got %q", out)
+	}
+	code, got, errOut := runCommand([]string{"dearmor", "--encoding", "json"}, out)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+	if got != data {
+		t.Errorf("Expected %q, got %q", data, got)
+	}
+}
+
+func TestDearmorErrors(t *testing.T) {
+	_, armored, _ := runCommand([]string{"armor"}, "hello, world")
+	lines := strings.Split(armored, "\n")
+	lines[2] = "X" + lines[2][1:]
+	damaged := strings.Join(lines, "\n")
+
+	cases := []struct {
+		args  []string
+		input string
+		code  int
+	}{
+		{[]string{"dearmor"}, "no block here\n", exitError},
+		{[]string{"dearmor"}, damaged, exitError},
+		{[]string{"dearmor"}, armored[:len(armored)/2], exitError},
+		{[]string{"armor", "-H", "no separator"}, "", exitUsage},
+		{[]string{"armor", "-H", ": empty key"}, "", exitUsage},
+		{[]string{"dearmor", "-w", "0"}, armored, exitUsage},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, _, errOut := runCommand(tc.args, tc.input)
+			if code != tc.code {
+				t.Errorf("Expected exit code %d, got %d", tc.code, code)
+			}
+			if errOut == "" {
+				t.Errorf("Expected message on standard error, got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// An atomicFile is a temporary file that takes the place of the file at path
+// when committed, so that readers of path never see it partly written.
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+// createAtomic creates an atomicFile for path in the same directory, so that
+// it can be renamed over path.
+func createAtomic(path string) (*atomicFile, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+// Commit writes f to disk and renames it to its path, replacing any file
+// there. The file keeps the permissions of the file it replaces, if any.
+func (f *atomicFile) Commit() error {
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(f.path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	err := f.Chmod(mode)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Abort closes and removes f, leaving the file at its path untouched.
+func (f *atomicFile) Abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/sha256"
+	"hash"
+	"hash/crc32"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mtraver/base91"
+)
+
+// checksums maps the names accepted by --checksum to their hash functions.
+var checksums = map[string]func() hash.Hash{
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	"sha256": sha256.New,
+}
+
+// checksumNames returns the names accepted by --checksum, in order, separated
+// by commas.
+func checksumNames() string {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// withChecksum returns a function like fn that, when encoding, appends the sum
+// computed by a hash from newHash to the data, and when decoding, checks the
+// sum at the end of the decoded data and removes it, in the manner of
+// EncodeWithHash and DecodeWithHash. Output that fails the check has already
+// been written when it fails, so that data of any size can be streamed.
+func withChecksum(fn func(w io.Writer, r io.Reader) error, newHash func() hash.Hash, decode bool) func(w io.Writer, r io.Reader) error {
+	return func(w io.Writer, r io.Reader) error {
+		h := newHash()
+		if !decode {
+			return fn(w, io.MultiReader(io.TeeReader(r, h), &sumReader{h: h}))
+		}
+		cw := &checkWriter{w: w, h: h}
+		if err := fn(cw, r); err != nil {
+			return err
+		}
+		return cw.check()
+	}
+}
+
+// A sumReader reads the sum computed by h, as of its first read.
+type sumReader struct {
+	h   hash.Hash
+	sum []byte
+}
+
+func (s *sumReader) Read(p []byte) (int, error) {
+	if s.sum == nil {
+		s.sum = s.h.Sum(nil)
+	}
+	if len(s.sum) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, s.sum)
+	s.sum = s.sum[n:]
+	return n, nil
+}
+
+// A checkWriter writes data to w and adds it to h, except for the last
+// h.Size() bytes written to it, which it holds back as the sum to check.
+type checkWriter struct {
+	w    io.Writer
+	h    hash.Hash
+	held []byte
+}
+
+func (c *checkWriter) Write(p []byte) (int, error) {
+	c.held = append(c.held, p...)
+	if n := len(c.held) - c.h.Size(); n > 0 {
+		c.h.Write(c.held[:n])
+		if _, err := c.w.Write(c.held[:n]); err != nil {
+			return 0, err
+		}
+		c.held = append(c.held[:0], c.held[n:]...)
+	}
+	return len(p), nil
+}
+
+// check returns base91.ErrChecksum unless the bytes held back are the sum of
+// those written before them.
+func (c *checkWriter) check() error {
+	if len(c.held) < c.h.Size() || string(c.h.Sum(nil)) != string(c.held) {
+		return base91.ErrChecksum
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+func TestChecksum(t *testing.T) {
+	data := strings.Repeat("checked data ", 100)
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--checksum", "crc32", "-w", "0"}, base91.StdEncoding.EncodeWithChecksum([]byte(data))},
+		{[]string{"--checksum", "sha256", "-w", "0"}, base91.StdEncoding.EncodeWithHash([]byte(data), sha256.New(), 0)},
+		{[]string{"--checksum", "crc32", "--numeric", "-w", "0"}, ""},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, out, errOut := runCommand(tc.args, data)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if tc.want != "" && out != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, out)
+			}
+
+			code, got, errOut := runCommand(append(tc.args, "-d"), out)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if got != data {
+				t.Errorf("Expected %q, got %q", data, got)
+			}
+		})
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	data := "hello, world"
+	out := filepath.Join(t.TempDir(), "out")
+	if err := os.WriteFile(out, []byte("previous"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	damaged, _ := base91.StdEncoding.DecodeString(base91.StdEncoding.EncodeWithChecksum([]byte(data)))
+	damaged[0] ^= 1
+
+	cases := []string{
+		base91.StdEncoding.EncodeToString(damaged),
+		base91.StdEncoding.EncodeToString([]byte(data)),
+		base91.StdEncoding.EncodeToString([]byte("abc")),
+		"",
+	}
+	for i, input := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, _, errOut := runCommand([]string{"-d", "--checksum", "crc32", "-o", out}, input)
+			if code != exitError {
+				t.Errorf("Expected exit code %d, got %d", exitError, code)
+			}
+			if !strings.Contains(errOut, base91.ErrChecksum.Error()) {
+				t.Errorf("Expected checksum error, got %q", errOut)
+			}
+			if got, _ := os.ReadFile(out); string(got) != "previous" {
+				t.Errorf("Expected output file to be left untouched, got %q", got)
+			}
+		})
+	}
+
+	if code, _, _ := runCommand([]string{"--checksum", "md5"}, data); code != exitUsage {
+		t.Errorf("Expected exit code %d for unknown checksum, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,382 @@
+// Command base91 encodes or decodes standard input to standard output, in the
+// manner of base64(1).
+//
+// Usage:
+//
+//	base91 [-d] [-i | --strict] [--numeric] [--checksum hash] [-w cols] [-o output] [-q] [--encoding name | --alphabet chars] [file ...]
+//	base91 -r [--include glob] [--exclude glob] [--suffix suffix] [options] dir
+//	base91 armor [-H 'Key: Value'] [-o output] [-q] [--encoding name | --alphabet chars] [file ...]
+//	base91 dearmor [-o output] [-q] [--encoding name | --alphabet chars] [file ...]
+//	base91 vectors [--format json|tsv]
+//
+// By default, base91 encodes its input using the standard alphabet, wrapping
+// lines at 76 columns, or at the number given with -w; -w 0 turns wrapping
+// off, and the trailing newline with it. With -d, it decodes its input
+// instead, skipping line breaks, and with -i as well, skipping any other bytes
+// outside the alphabet. With -d and --strict, it instead rejects input that
+// encoding with the same flags could not have produced: any byte outside the
+// alphabet other than '\n', such as '\r' or a space, a line of other than
+// cols symbols before the last, an empty line, a missing trailing newline or
+// any '\n' at all if -w is 0, and a final group of symbols that the encoder
+// would not have written. This makes it suitable for validating data files.
+//
+// With --numeric, base91 encodes its input as a single number in base 91, as
+// NumericEncoding does, which suits fingerprints and other short values; the
+// output is never wrapped, and ends with a newline unless -w is 0. Since the
+// whole input is converted at once, in time quadratic in its length, it is
+// not meant for large files.
+//
+// With --checksum crc32 or --checksum sha256, base91 appends a checksum of the
+// data, computed with the named hash, to the data before encoding it, as
+// EncodeWithHash does, and when decoding checks the checksum at the end of the
+// decoded data and removes it. Since output is streamed, data that fails the
+// check has already been written to standard output; with -o, the output file
+// is left untouched.
+//
+// The alphabet is the standard one unless --encoding names one of the
+// package's variants, such as json or url-tolerant, or --alphabet gives the 91
+// characters of a custom one.
+//
+// With -r, base91 converts each file in the directory tree dir, and writes the
+// result to the same place in the tree given by -o, or if there is none next
+// to the file. When encoding, it adds the suffix given by --suffix, .b91 by
+// default, to the name of each file, and skips files that already have it;
+// when decoding, it converts only files with the suffix and removes it.
+// --include limits it to files whose names match any of the given globs, and
+// --exclude skips files and directories whose names match any of them. Each
+// output file is replaced only once it is complete. Progress is not shown.
+//
+// The armor subcommand encodes its input as an armored block, a PEM-like text
+// envelope that carries the headers given with -H and a checksum of the data,
+// for pasting into tickets and chat. The dearmor subcommand finds the armored
+// blocks in its input, skipping any text around them, checks them, and writes
+// out their data.
+//
+// The vectors subcommand writes the test vectors returned by InteropVectors,
+// which cover every length of input up to 32 bytes and every kind of symbol
+// group at the end of the encoding, as JSON with data in base64 or as TSV with
+// data in hexadecimal, for checking implementations in other languages.
+//
+// base91 reads the named files one after another, or standard input if there
+// are none; a file named "-" also stands for standard input. It writes to
+// standard output, or with -o to the named file, which it replaces only once
+// all of the output has been written. It exits with status 0 on success, 1 if
+// it fails to read, convert, or write the data, and 2 if its command line is
+// invalid.
+//
+// When base91 reads files or writes to a file, and its standard error is a
+// terminal, it shows there how much of the input it has read, as a percentage
+// if it knows the size of the input, and how fast. -q turns this off.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mtraver/base91"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1 // Failure while encoding or decoding.
+	exitUsage = 2 // Invalid command line.
+)
+
+// defaultWrap is the number of columns at which encoded output is wrapped
+// unless -w says otherwise.
+const defaultWrap = 76
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments and streams, and returns its
+// exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "armor":
+			return runArmor(args[1:], stdin, stdout, stderr)
+		case "dearmor":
+			return runDearmor(args[1:], stdin, stdout, stderr)
+		case "vectors":
+			return runVectors(args[1:], stdout, stderr)
+		}
+	}
+
+	fs := newFlagSet("base91", "[-d] [-i | --strict] [--numeric] [--checksum hash] [-w cols] [-o output] [-q] [--encoding name | --alphabet chars] [file ...]\n"+
+		"       base91 -r [--include glob] [--exclude glob] [--suffix suffix] [options] dir", stderr)
+	var (
+		decode, ignoreGarbage bool
+		strict                bool
+		numeric               bool
+		checksum              string
+		wrap                  int
+		common                commonFlags
+		tree                  treeFlags
+	)
+	fs.BoolVar(&decode, "d", false, "decode data")
+	fs.BoolVar(&decode, "decode", false, "decode data")
+	fs.BoolVar(&ignoreGarbage, "i", false, "when decoding, ignore bytes outside the alphabet")
+	fs.BoolVar(&ignoreGarbage, "ignore-garbage", false, "when decoding, ignore bytes outside the alphabet")
+	fs.BoolVar(&strict, "strict", false, "when decoding, accept only what encoding with the same flags produces")
+	fs.BoolVar(&numeric, "numeric", false, "encode data as a single base 91 number, as NumericEncoding does")
+	fs.StringVar(&checksum, "checksum", "", "append a checksum computed with `hash` to the data, or check and remove it when decoding: "+checksumNames())
+	fs.IntVar(&wrap, "w", defaultWrap, "wrap encoded lines after `cols` characters (0 for no wrapping)")
+	fs.IntVar(&wrap, "wrap", defaultWrap, "wrap encoded lines after `cols` characters (0 for no wrapping)")
+	common.register(fs)
+	tree.register(fs)
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return usageExitCode(err)
+	}
+	if tree.recursive && len(files) != 1 {
+		fmt.Fprintln(stderr, "base91: -r requires exactly one directory")
+		return exitUsage
+	}
+	if strict && ignoreGarbage {
+		fmt.Fprintln(stderr, "base91: -i and --strict cannot both be given")
+		return exitUsage
+	}
+	newHash, ok := checksums[checksum]
+	if checksum != "" && !ok {
+		fmt.Fprintf(stderr, "base91: unknown checksum %q; known checksums are %s\n", checksum, checksumNames())
+		return exitUsage
+	}
+	if tree.recursive && tree.suffix == "" {
+		fmt.Fprintln(stderr, "base91: --suffix must not be empty")
+		return exitUsage
+	}
+	if wrap < 0 {
+		fmt.Fprintln(stderr, "base91: invalid wrap size:", wrap)
+		return exitUsage
+	}
+	base, err := common.baseEncoding(fs)
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitUsage
+	}
+
+	opts := []base91.Option{base91.Wrap(wrap)}
+	if wrap > 0 {
+		opts = append(opts, base91.TrailingNewline())
+	}
+	if ignoreGarbage {
+		opts = append(opts, base91.SkipInvalid())
+	}
+	if strict {
+		opts = append(opts, base91.Strict())
+	}
+	enc := base91.NewEncodingWithOptions(base.Alphabet(), opts...)
+
+	fn := func(w io.Writer, r io.Reader) error {
+		if numeric {
+			return convertNumeric(w, r, base, decode, ignoreGarbage, strict, wrap > 0)
+		}
+		if decode {
+			_, err := io.Copy(w, base91.NewDecoder(enc, r))
+			return err
+		}
+		return encode(base91.NewEncoder(enc, w), r)
+	}
+	if newHash != nil {
+		fn = withChecksum(fn, newHash, decode)
+	}
+	if tree.recursive {
+		err = tree.convert(os.DirFS(files[0]), files[0], common.output, decode, fn)
+	} else {
+		err = common.convert(files, stdin, stdout, stderr, fn)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// newFlagSet returns a flag set for the command or subcommand name, whose
+// usage message shows the given synopsis and is written to stderr.
+func newFlagSet(name, synopsis string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage:", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// usageExitCode returns the exit code for err, an error from parsing the
+// command line, which the flag package has already reported.
+func usageExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// commonFlags holds the flags that the command and its subcommands share.
+type commonFlags struct {
+	output             string
+	alphabet, encoding string
+	quiet              bool
+}
+
+// register defines the flags in fs.
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.output, "o", "", "write output to `file` instead of standard output")
+	fs.StringVar(&c.output, "output", "", "write output to `file` instead of standard output")
+	fs.BoolVar(&c.quiet, "q", false, "do not show progress")
+	fs.BoolVar(&c.quiet, "quiet", false, "do not show progress")
+	fs.StringVar(&c.alphabet, "alphabet", "", "use the 91-character alphabet `chars`")
+	fs.StringVar(&c.encoding, "encoding", "std", "use the alphabet of the named encoding: "+encodingNames())
+}
+
+// encodings maps the names accepted by --encoding to their encodings.
+var encodings = map[string]*base91.Encoding{
+	"std":          base91.StdEncoding,
+	"json":         base91.JSONEncoding,
+	"xml":          base91.XMLEncoding,
+	"csv":          base91.CSVEncoding,
+	"shell":        base91.ShellEncoding,
+	"quote-safe":   base91.QuoteSafeEncoding,
+	"url-tolerant": base91.URLTolerantEncoding,
+}
+
+// encodingNames returns the names accepted by --encoding, in order, separated
+// by commas.
+func encodingNames() string {
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// baseEncoding returns the encoding whose alphabet the command uses: one with
+// the alphabet given by --alphabet, if set, or else the one named by
+// --encoding. The two flags may not both be set. fs is the flag set that c
+// was registered with, after parsing.
+func (c *commonFlags) baseEncoding(fs *flag.FlagSet) (*base91.Encoding, error) {
+	setEncoding := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "encoding" {
+			setEncoding = true
+		}
+	})
+
+	if c.alphabet != "" {
+		if setEncoding {
+			return nil, errors.New("--alphabet and --encoding cannot both be given")
+		}
+		return base91.NewEncodingStrict(c.alphabet)
+	}
+	enc, ok := encodings[c.encoding]
+	if !ok {
+		return nil, errors.New("unknown encoding " + strconv.Quote(c.encoding) + "; known encodings are " + encodingNames())
+	}
+	return enc, nil
+}
+
+// parseArgs parses the flags in args, which may come before, after, or between
+// file names, and returns the file names. Everything after "--" is a file
+// name.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return files, nil
+		}
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(files, rest...), nil
+		}
+		files = append(files, rest[0])
+		args = rest[1:]
+	}
+}
+
+// convert passes the concatenated contents of files to fn as r, and writes
+// what fn writes to w to the file named by c.output. A file named "-", or no
+// files at all, stands for stdin, and an empty output for stdout. The output
+// file is written in full under a temporary name and then renamed, so it is
+// replaced only if fn succeeds. Unless c.quiet is set, convert shows the
+// progress of reading files, or of writing an output file, on stderr if it is
+// a terminal.
+func (c *commonFlags) convert(files []string, stdin io.Reader, stdout, stderr io.Writer, fn func(w io.Writer, r io.Reader) error) error {
+	var readers []io.Reader
+	var total int64
+	for _, name := range files {
+		if name == "-" {
+			readers = append(readers, stdin)
+			total = -1
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		readers = append(readers, f)
+		if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() && total >= 0 {
+			total += fi.Size()
+		} else {
+			total = -1
+		}
+	}
+	r := stdin
+	if len(files) > 0 {
+		r = io.MultiReader(readers...)
+	} else {
+		total = -1
+	}
+
+	if !c.quiet && (len(files) > 0 || c.output != "") && isTerminal(stderr) {
+		p := newProgress(stderr, total)
+		defer p.done()
+		r = &progressReader{r: r, p: p}
+	}
+
+	if c.output == "" {
+		w := bufio.NewWriter(stdout)
+		if err := fn(w, r); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	f, err := createAtomic(c.output)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = fn(w, r)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// encode copies the data read from r to the encoder e and closes it.
+func encode(e io.WriteCloser, r io.Reader) error {
+	if _, err := io.Copy(e, r); err != nil {
+		return err
+	}
+	return e.Close()
+}
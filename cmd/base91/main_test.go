@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+// runCommand runs the command with args and input, and returns its exit code,
+// standard output, and standard error.
+func runCommand(args []string, input string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(input), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestEncode(t *testing.T) {
+	data := strings.Repeat("hello, world\n", 20)
+	code, out, errOut := runCommand(nil, data)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for i, line := range lines {
+		if len(line) > 76 || i < len(lines)-1 && len(line) != 76 {
+			t.Errorf("Line %d has length %d", i, len(line))
+		}
+	}
+	if want := base91.StdEncoding.EncodeToString([]byte(data)); strings.Join(lines, "") != want {
+		t.Errorf("Expected %q, got %q", want, out)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := "hello, world"
+	for _, flag := range []string{"-d", "--decode"} {
+		code, out, errOut := runCommand([]string{flag}, base91.StdEncoding.EncodeToString([]byte(data))+"\n")
+		if code != exitOK {
+			t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+		}
+		if out != data {
+			t.Errorf("Expected %q, got %q", data, out)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for i, n := range []int{0, 1, 2, 57, 1000, 10000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			data := make([]byte, n)
+			for j := range data {
+				data[j] = byte(j * 7)
+			}
+			_, encoded, _ := runCommand(nil, string(data))
+			code, out, errOut := runCommand([]string{"-d"}, encoded)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if out != string(data) {
+				t.Errorf("Expected %q, got %q", data, out)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		args  []string
+		input string
+		code  int
+	}{
+		{[]string{"-d"}, "abc def", exitError},
+		{[]string{"-x"}, "", exitUsage},
+		{[]string{"no-such-file"}, "", exitError},
+		{[]string{"-w"}, "", exitUsage},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, _, errOut := runCommand(tc.args, tc.input)
+			if code != tc.code {
+				t.Errorf("Expected exit code %d, got %d", tc.code, code)
+			}
+			if errOut == "" {
+				t.Errorf("Expected message on standard error, got none")
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	data := strings.Repeat("x", 100)
+	encoded := base91.StdEncoding.EncodeToString([]byte(data))
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w", "0"}, encoded},
+		{[]string{"--wrap=0"}, encoded},
+		{[]string{"-w", "100"}, encoded[:100] + "\n" + encoded[100:] + "\n"},
+		{[]string{"-w", "200"}, encoded + "\n"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, out, errOut := runCommand(tc.args, data)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if out != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, out)
+			}
+		})
+	}
+
+	if code, _, _ := runCommand([]string{"-w", "-1"}, data); code != exitUsage {
+		t.Errorf("Expected exit code %d for negative wrap, got %d", exitUsage, code)
+	}
+}
+
+func TestIgnoreGarbage(t *testing.T) {
+	data := "hello, world"
+	encoded := base91.StdEncoding.EncodeToString([]byte(data))
+	noisy := encoded[:5] + " \t'" + encoded[5:] + "\\"
+	for _, flag := range []string{"-i", "--ignore-garbage"} {
+		code, out, errOut := runCommand([]string{"-d", flag}, noisy)
+		if code != exitOK {
+			t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+		}
+		if out != data {
+			t.Errorf("Expected %q, got %q", data, out)
+		}
+	}
+	if code, _, _ := runCommand([]string{"-d"}, noisy); code != exitError {
+		t.Errorf("Expected exit code %d without -i, got %d", exitError, code)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.WriteFile(a, []byte("hello, "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := base91.StdEncoding.EncodeToString([]byte("hello, stdin world")) + "\n"
+
+	cases := [][]string{
+		{a, "-", b},
+		{"-w", "76", a, "-", b},
+		{a, "-w", "76", "-", b},
+		{"--", a, "-", b},
+	}
+	for i, args := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, out, errOut := runCommand(args, "stdin ")
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if out != want {
+				t.Errorf("Expected %q, got %q", want, out)
+			}
+		})
+	}
+}
+
+func TestOutput(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in"), filepath.Join(dir, "out")
+	data := "hello, world"
+	encoded := base91.StdEncoding.EncodeToString([]byte(data)) + "\n"
+	if err := os.WriteFile(in, []byte(encoded), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, errOut := runCommand([]string{"-d", in, "-o", out}, ""); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("Expected %q, got %q", data, got)
+	}
+
+	// A failed conversion leaves the output file as it was.
+	if code, _, _ := runCommand([]string{"-d", "-o", out}, "bad input"); code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+	got, err = os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("Expected %q to be left untouched, got %q", data, got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected only input and output files, got %d entries", len(entries))
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	data := `"quoted", with commas`
+	custom := base91.URLTolerantEncoding.Alphabet()
+	cases := []struct {
+		args []string
+		enc  *base91.Encoding
+	}{
+		{[]string{"--encoding", "std"}, base91.StdEncoding},
+		{[]string{"--encoding", "json"}, base91.JSONEncoding},
+		{[]string{"--encoding=csv"}, base91.CSVEncoding},
+		{[]string{"--encoding", "quote-safe"}, base91.QuoteSafeEncoding},
+		{[]string{"--alphabet", custom}, base91.URLTolerantEncoding},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			args := append([]string{"-w", "0"}, tc.args...)
+			code, out, errOut := runCommand(args, data)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if want := tc.enc.EncodeToString([]byte(data)); out != want {
+				t.Errorf("Expected %q, got %q", want, out)
+			}
+
+			code, decoded, errOut := runCommand(append(args, "-d"), out)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if decoded != data {
+				t.Errorf("Expected %q, got %q", data, decoded)
+			}
+		})
+	}
+}
+
+func TestAlphabetInvalid(t *testing.T) {
+	std := base91.StdEncoding.Alphabet()
+	cases := [][]string{
+		{"--encoding", "base64"},
+		{"--alphabet", std[:90]},
+		{"--alphabet", std[:90] + "A"},
+		{"--alphabet", std, "--encoding", "std"},
+	}
+
+	for i, args := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, _, errOut := runCommand(args, "")
+			if code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+			}
+			if errOut == "" {
+				t.Errorf("Expected message on standard error, got none")
+			}
+		})
+	}
+}
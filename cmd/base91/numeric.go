@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+
+	"github.com/mtraver/base91"
+)
+
+// convertNumeric reads all of r and writes its encoding, or if decode is true
+// its decoding, using the NumericEncoding with the alphabet of enc to w. When
+// decoding, it ignores line breaks, and if ignoreGarbage is true any other
+// bytes outside the alphabet; if strict is true, it instead accepts only the
+// output of encoding. When encoding, it ends the output with a newline if
+// newline is true.
+func convertNumeric(w io.Writer, r io.Reader, enc *base91.Encoding, decode, ignoreGarbage, strict, newline bool) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	numeric := enc.Numeric()
+
+	if !decode {
+		out := numeric.EncodeToString(data)
+		if newline && out != "" {
+			out += "\n"
+		}
+		_, err := io.WriteString(w, out)
+		return err
+	}
+
+	var symbol [256]bool
+	for _, c := range []byte(enc.Alphabet()) {
+		symbol[c] = true
+	}
+	src := data[:0]
+	if strict {
+		src = data
+		if newline && len(src) > 0 && src[len(src)-1] == '\n' {
+			src = src[:len(src)-1]
+		}
+	} else {
+		for _, c := range data {
+			if c != '\r' && c != '\n' && (symbol[c] || !ignoreGarbage) {
+				src = append(src, c)
+			}
+		}
+	}
+	decoded, err := numeric.DecodeString(string(src))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(decoded)
+	return err
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+func TestNumeric(t *testing.T) {
+	fingerprint := "\x00\x01\x02\xfe\xff"
+	encoded := base91.StdEncoding.Numeric().EncodeToString([]byte(fingerprint))
+	cases := []struct {
+		args        []string
+		input, want string
+	}{
+		{[]string{"--numeric"}, fingerprint, encoded + "\n"},
+		{[]string{"--numeric", "-w", "0"}, fingerprint, encoded},
+		{[]string{"--numeric"}, "", ""},
+		{[]string{"--numeric", "-d"}, encoded + "\r\n", fingerprint},
+		{[]string{"--numeric", "-d", "-i"}, " " + encoded[:2] + "'" + encoded[2:], fingerprint},
+		{[]string{"--numeric", "--encoding", "json"}, "\x01\x00", "C:\n"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, out, errOut := runCommand(tc.args, tc.input)
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if out != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, out)
+			}
+		})
+	}
+
+	if code, _, _ := runCommand([]string{"--numeric", "-d"}, encoded[:2]+" "+encoded[2:]); code != exitError {
+		t.Errorf("Expected exit code %d without -i, got %d", exitError, code)
+	}
+}
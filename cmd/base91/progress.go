@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progressInterval is how often the progress display is updated.
+const progressInterval = 250 * time.Millisecond
+
+// isTerminal reports whether w is a terminal. It is a variable so that tests
+// can replace it.
+var isTerminal = isTerminalFile
+
+// isTerminalFile reports whether w is a file that is a terminal.
+func isTerminalFile(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// now returns the current time. It is a variable so that tests can replace it.
+var now = time.Now
+
+// A progress shows on a terminal how many bytes of input have been read, out
+// of how many, and how fast, rewriting a single line as reading goes on.
+type progress struct {
+	w     io.Writer
+	total int64 // Size of the input, or -1 if it is not known.
+	n     int64 // Number of bytes read so far.
+	start time.Time
+	shown time.Time // When the line was last written.
+}
+
+// newProgress returns a progress that writes to w, for input of total bytes,
+// or of unknown size if total is negative.
+func newProgress(w io.Writer, total int64) *progress {
+	t := now()
+	return &progress{w: w, total: total, start: t, shown: t}
+}
+
+// add records that n more bytes have been read, and updates the line if it
+// has not been updated for a while.
+func (p *progress) add(n int) {
+	p.n += int64(n)
+	if t := now(); t.Sub(p.shown) >= progressInterval {
+		p.shown = t
+		p.show(t)
+	}
+}
+
+// done writes the final state of the line and ends it.
+func (p *progress) done() {
+	p.show(now())
+	fmt.Fprintln(p.w)
+}
+
+// show writes the line as of time t.
+func (p *progress) show(t time.Time) {
+	line := formatBytes(p.n)
+	if p.total >= 0 {
+		percent := int64(100)
+		if p.total > 0 {
+			percent = 100 * p.n / p.total
+		}
+		line += fmt.Sprintf(" / %s (%d%%)", formatBytes(p.total), percent)
+	}
+	if elapsed := t.Sub(p.start).Seconds(); elapsed > 0 {
+		line += fmt.Sprintf(", %s/s", formatBytes(int64(float64(p.n)/elapsed)))
+	}
+	// Clear the rest of the terminal line, which may hold a longer one.
+	fmt.Fprintf(p.w, "\r%s\x1b[K", line)
+}
+
+// formatBytes returns n as a number of bytes for people to read, such as
+// "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// A progressReader is a reader that records what it reads in a progress.
+type progressReader struct {
+	r io.Reader
+	p *progress
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.p.add(n)
+	return n, err
+}
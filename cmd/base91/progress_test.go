@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	cases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+		{1 << 62, "4.0 EiB"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := formatBytes(tc.n); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+// fakeClock replaces now with a clock that advances by step on each call,
+// until the test ends.
+func fakeClock(t *testing.T, step time.Duration) {
+	t.Helper()
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		clock = clock.Add(step)
+		return clock
+	}
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestProgress(t *testing.T) {
+	fakeClock(t, time.Second)
+	var b strings.Builder
+	p := newProgress(&b, 4<<20)
+	p.add(1 << 20)
+	p.add(1 << 20)
+	p.done()
+
+	lines := strings.Split(b.String(), "\r")
+	want := []string{
+		"",
+		"1.0 MiB / 4.0 MiB (25%), 1.0 MiB/s\x1b[K",
+		"2.0 MiB / 4.0 MiB (50%), 1.0 MiB/s\x1b[K",
+		"2.0 MiB / 4.0 MiB (50%), 682.7 KiB/s\x1b[K\n",
+	}
+	if fmt.Sprintf("%q", lines) != fmt.Sprintf("%q", want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestProgressUnknownSize(t *testing.T) {
+	fakeClock(t, time.Millisecond)
+	var b strings.Builder
+	p := newProgress(&b, -1)
+	p.add(100)
+	p.done()
+
+	// Updates come no more often than progressInterval.
+	if want := "\r100 B, 48.8 KiB/s\x1b[K\n"; b.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.String())
+	}
+}
+
+func TestProgressCommand(t *testing.T) {
+	isTerminal = func(io.Writer) bool { return true }
+	defer func() { isTerminal = isTerminalFile }()
+
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in")
+	if err := os.WriteFile(in, make([]byte, 1000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args     []string
+		progress string // Expected in standard error, or "" for no progress.
+	}{
+		{[]string{in}, "1000 B / 1000 B (100%)"},
+		{[]string{"-o", filepath.Join(dir, "out")}, "0 B"},
+		{[]string{in, "-"}, "1000 B,"},
+		{[]string{"-q", in}, ""},
+		{[]string{"--quiet", in}, ""},
+		{nil, ""},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, _, errOut := runCommand(tc.args, "")
+			if code != exitOK {
+				t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+			}
+			if tc.progress == "" && errOut != "" || !strings.Contains(errOut, tc.progress) {
+				t.Errorf("Expected progress %q, got %q", tc.progress, errOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultSuffix is the suffix that recursive mode adds to the names of the
+// files it encodes, and removes from those it decodes.
+const defaultSuffix = ".b91"
+
+// treeFlags holds the flags for converting a directory tree.
+type treeFlags struct {
+	recursive        bool
+	include, exclude globList
+	suffix           string
+}
+
+// register defines the flags in fs.
+func (t *treeFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&t.recursive, "r", false, "convert the files in a directory tree")
+	fs.BoolVar(&t.recursive, "recursive", false, "convert the files in a directory tree")
+	fs.Var(&t.include, "include", "with -r, convert only files whose names match `glob` (may be repeated)")
+	fs.Var(&t.exclude, "exclude", "with -r, skip files and directories whose names match `glob` (may be repeated)")
+	fs.StringVar(&t.suffix, "suffix", defaultSuffix, "with -r, the `suffix` of the names of encoded files")
+}
+
+// convert converts the files in the tree fsys, which is the directory dir,
+// and writes each result to the same place in the tree rooted at output, or
+// if output is empty next to the file in dir. When encoding, it adds t.suffix
+// to the name of each output file, and skips files whose names already end
+// with it; when decoding, it converts only files whose names end with t.suffix
+// and removes it. fn converts each file as for commonFlags.convert. Each
+// output file is replaced only once it has been written in full.
+func (t *treeFlags) convert(fsys fs.FS, dir, output string, decode bool, fn func(w io.Writer, r io.Reader) error) error {
+	if output == "" {
+		output = dir
+	}
+	// Skip the output tree if it is inside the input tree, so that files are
+	// not converted again.
+	skip := ""
+	if rel, err := filepath.Rel(dir, output); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		skip = filepath.ToSlash(rel)
+	}
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if name == skip || t.exclude.match(d.Name()) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || t.exclude.match(d.Name()) ||
+			len(t.include) > 0 && !t.include.match(d.Name()) ||
+			strings.HasSuffix(name, t.suffix) != decode {
+			return nil
+		}
+
+		outName := name + t.suffix
+		if decode {
+			outName = strings.TrimSuffix(name, t.suffix)
+		}
+		if err := convertFile(fsys, name, filepath.Join(output, filepath.FromSlash(outName)), fn); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Join(dir, filepath.FromSlash(name)), err)
+		}
+		return nil
+	})
+}
+
+// convertFile passes the contents of the file name in fsys to fn, and writes
+// what fn writes to the file at outPath, creating its directory if need be.
+func convertFile(fsys fs.FS, name, outPath string, fn func(w io.Writer, r io.Reader) error) error {
+	in, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	out, err := createAtomic(outPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	err = fn(w, in)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		out.Abort()
+		return err
+	}
+	return out.Commit()
+}
+
+// globList is a flag.Value that collects glob patterns, as understood by
+// path.Match.
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	*g = append(*g, pattern)
+	return nil
+}
+
+// match reports whether name matches any of the patterns in g.
+func (g globList) match(name string) bool {
+	for _, pattern := range g {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
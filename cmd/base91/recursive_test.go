@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+// writeTree creates the files in tree, which maps slash-separated paths to
+// contents, under dir.
+func writeTree(t *testing.T, dir string, tree map[string]string) {
+	t.Helper()
+	for name, data := range tree {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree returns the files under dir, as a map from slash-separated paths to
+// contents.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		tree[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// treeNames returns the sorted paths in tree.
+func treeNames(tree map[string]string) string {
+	var names []string
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestRecursive(t *testing.T) {
+	src, dst, back := t.TempDir(), t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{
+		"a.bin":          "first",
+		"sub/b.bin":      "second",
+		"sub/deep/c.bin": "third",
+		"sub/notes.txt":  "skipped",
+		"skip/d.bin":     "skipped",
+	})
+
+	code, _, errOut := runCommand([]string{"-r", src, "-o", dst, "--include", "*.bin", "--exclude", "skip"}, "")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+	encoded := readTree(t, dst)
+	if want := "a.bin.b91 sub/b.bin.b91 sub/deep/c.bin.b91"; treeNames(encoded) != want {
+		t.Errorf("Expected files %s, got %s", want, treeNames(encoded))
+	}
+	if want := base91.StdEncoding.EncodeToString([]byte("second")) + "\n"; encoded["sub/b.bin.b91"] != want {
+		t.Errorf("Expected %q, got %q", want, encoded["sub/b.bin.b91"])
+	}
+
+	code, _, errOut = runCommand([]string{"-d", "--recursive", dst, "-o", back}, "")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+	decoded := readTree(t, back)
+	if want := "a.bin sub/b.bin sub/deep/c.bin"; treeNames(decoded) != want {
+		t.Errorf("Expected files %s, got %s", want, treeNames(decoded))
+	}
+	if decoded["sub/deep/c.bin"] != "third" {
+		t.Errorf("Expected %q, got %q", "third", decoded["sub/deep/c.bin"])
+	}
+}
+
+func TestRecursiveInPlace(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a": "first", "sub/b": "second"})
+
+	// Running twice does not encode the encoded files again.
+	for i := 0; i < 2; i++ {
+		if code, _, errOut := runCommand([]string{"-r", "--suffix", ".txt", dir}, ""); code != exitOK {
+			t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+		}
+	}
+	if want := "a a.txt sub/b sub/b.txt"; treeNames(readTree(t, dir)) != want {
+		t.Errorf("Expected files %s, got %s", want, treeNames(readTree(t, dir)))
+	}
+}
+
+func TestRecursiveOutputInside(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a": "first"})
+
+	for i := 0; i < 2; i++ {
+		if code, _, errOut := runCommand([]string{"-r", dir, "-o", filepath.Join(dir, "out")}, ""); code != exitOK {
+			t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+		}
+	}
+	if want := "a out/a.b91"; treeNames(readTree(t, dir)) != want {
+		t.Errorf("Expected files %s, got %s", want, treeNames(readTree(t, dir)))
+	}
+}
+
+func TestRecursiveErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"bad.b91": "not base91 data"})
+
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"-r"}, exitUsage},
+		{[]string{"-r", dir, dir}, exitUsage},
+		{[]string{"-r", "--suffix", "", dir}, exitUsage},
+		{[]string{"-r", "--include", "[", dir}, exitUsage},
+		{[]string{"-r", filepath.Join(dir, "missing")}, exitError},
+		{[]string{"-r", "-d", dir}, exitError},
+	}
+
+	for _, tc := range cases {
+		code, _, errOut := runCommand(tc.args, "")
+		if code != tc.code {
+			t.Errorf("%q: expected exit code %d, got %d", tc.args, tc.code, code)
+		}
+		if errOut == "" {
+			t.Errorf("%q: expected message on standard error, got none", tc.args)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output for failed file, got %v", err)
+	}
+}
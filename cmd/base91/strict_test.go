@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+func TestStrict(t *testing.T) {
+	data := "hello, world"
+	encoded := base91.StdEncoding.EncodeToString([]byte(data))
+	numeric := base91.StdEncoding.Numeric().EncodeToString([]byte(data))
+	wrapped := encoded[:6] + "\n" + encoded[6:12] + "\n" + encoded[12:] + "\n"
+
+	// "~~" decodes to one byte, with padding bits that the encoder would have
+	// left zero.
+	cases := []struct {
+		args  []string
+		input string
+		code  int
+	}{
+		{[]string{"--strict"}, encoded + "\n", exitOK},
+		{[]string{"--strict", "-w", "6"}, wrapped, exitOK},
+		{[]string{"--strict", "-w", "0"}, encoded, exitOK},
+		{[]string{"--strict", "--numeric"}, numeric + "\n", exitOK},
+		{nil, encoded + "\r\n", exitOK},
+		{[]string{"--strict"}, encoded + "\r\n", exitError},
+		{[]string{"--strict"}, encoded[:5] + " " + encoded[5:], exitError},
+		{[]string{"--strict", "-w", "0"}, encoded + "\n", exitError},
+		{nil, "~~", exitOK},
+		{[]string{"--strict"}, "~~", exitError},
+		{[]string{"--strict", "--numeric"}, numeric + "\r\n", exitError},
+		{[]string{"--strict", "--numeric", "-w", "0"}, numeric + "\n", exitError},
+		{[]string{"--strict", "-i"}, encoded, exitUsage},
+		{[]string{"--strict"}, encoded[:5] + "\n" + encoded[5:] + "\n", exitError},
+		{[]string{"--strict"}, encoded, exitError},
+		{[]string{"--strict"}, "\n" + encoded + "\n", exitError},
+		{[]string{"--strict"}, encoded + "\n\n", exitError},
+		{[]string{"--strict"}, "TPwJh>\nIo2\n\n\nTv!lE\n", exitError},
+		{[]string{"--strict"}, "TPwJh>\nIo2\n\n\nTv!lE", exitError},
+		{[]string{"--strict", "-w", "6"}, wrapped[:len(wrapped)-1], exitError},
+		{[]string{"--strict", "-w", "6"}, wrapped[:7] + "\n" + wrapped[7:], exitError},
+		{[]string{"--strict", "-w", "6"}, "\n" + wrapped, exitError},
+		{[]string{"--strict", "-w", "6"}, wrapped + "\n", exitError},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			code, out, errOut := runCommand(append([]string{"-d"}, tc.args...), tc.input)
+			if code != tc.code {
+				t.Fatalf("Expected exit code %d, got %d: %s", tc.code, code, errOut)
+			}
+			if code == exitOK && tc.input != "~~" && out != data {
+				t.Errorf("Expected %q, got %q", data, out)
+			}
+		})
+	}
+}
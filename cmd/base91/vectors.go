@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mtraver/base91"
+)
+
+// runVectors runs the vectors subcommand and returns its exit code.
+func runVectors(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("base91 vectors", "[--format json|tsv]", stderr)
+	var format string
+	fs.StringVar(&format, "format", "json", "write vectors in `format`: json or tsv")
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	w := bufio.NewWriter(stdout)
+	var err error
+	switch format {
+	case "json":
+		err = writeVectorsJSON(w, base91.InteropVectors())
+	case "tsv":
+		err = writeVectorsTSV(w, base91.InteropVectors())
+	default:
+		fmt.Fprintf(stderr, "base91: unknown format %q; known formats are json, tsv\n", format)
+		return exitUsage
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "base91:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// writeVectorsJSON writes vs to w as a JSON array, one vector per line, with
+// data in standard base64.
+func writeVectorsJSON(w io.Writer, vs []base91.Vector) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, v := range vs {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if i == 0 {
+			sep = "\n"
+		}
+		if _, err := fmt.Fprintf(w, "%s  %s", sep, b); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
+
+// writeVectorsTSV writes vs to w as tab-separated values, with a header line
+// and data in hexadecimal. No alphabet contains a tab or line break, so the
+// encoded data needs no quoting.
+func writeVectorsTSV(w io.Writer, vs []base91.Vector) error {
+	if _, err := io.WriteString(w, "name\tdata\tencoded\n"); err != nil {
+		return err
+	}
+	for _, v := range vs {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", v.Name, hex.EncodeToString(v.Data), v.Encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
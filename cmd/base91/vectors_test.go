@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtraver/base91"
+)
+
+func TestVectorsJSON(t *testing.T) {
+	for _, args := range [][]string{{"vectors"}, {"vectors", "--format", "json"}} {
+		code, out, errOut := runCommand(args, "")
+		if code != exitOK {
+			t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+		}
+		var vs []base91.Vector
+		if err := json.Unmarshal([]byte(out), &vs); err != nil {
+			t.Fatalf("Got error parsing JSON: %v", err)
+		}
+		if want := base91.InteropVectors(); fmt.Sprint(vs) != fmt.Sprint(want) {
+			t.Errorf("Expected %v, got %v", want, vs)
+		}
+	}
+}
+
+func TestVectorsTSV(t *testing.T) {
+	code, out, errOut := runCommand([]string{"vectors", "--format=tsv"}, "")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, errOut)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if lines[0] != "name\tdata\tencoded" {
+		t.Errorf("Expected header line, got %q", lines[0])
+	}
+
+	want := base91.InteropVectors()
+	if len(lines)-1 != len(want) {
+		t.Fatalf("Expected %d vectors, got %d", len(want), len(lines)-1)
+	}
+	for i, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			t.Fatalf("Expected 3 fields, got %q", line)
+		}
+		data, err := hex.DecodeString(fields[1])
+		if err != nil {
+			t.Fatalf("Got error decoding hex: %v", err)
+		}
+		got := base91.Vector{Name: fields[0], Data: data, Encoded: fields[2]}
+		if fmt.Sprint(got) != fmt.Sprint(want[i]) {
+			t.Errorf("Expected %v, got %v", want[i], got)
+		}
+	}
+}
+
+func TestVectorsInvalid(t *testing.T) {
+	for _, args := range [][]string{{"vectors", "--format", "xml"}, {"vectors", "extra"}} {
+		if code, _, _ := runCommand(args, ""); code != exitUsage {
+			t.Errorf("%v: expected exit code %d, got %d", args, exitUsage, code)
+		}
+	}
+}
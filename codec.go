@@ -0,0 +1,60 @@
+package base91
+
+// BinaryTextCodec is the method set of *base64.Encoding and *base32.Encoding.
+// Code written against it, rather than against one of those types, can use
+// base91 through the adapter returned by Codec without changes.
+type BinaryTextCodec interface {
+	Encode(dst, src []byte)
+	Decode(dst, src []byte) (int, error)
+	EncodedLen(n int) int
+	DecodedLen(n int) int
+	EncodeToString(src []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+// Codec returns an adapter that implements BinaryTextCodec using enc.
+//
+// The adapter differs from enc only in Encode, which, like that of
+// *base64.Encoding, returns nothing and writes exactly EncodedLen(len(src))
+// bytes, since callers written for base64 use all of them. The encoded length
+// of base91 depends on the data, so when the encoding is shorter than
+// EncodedLen, Encode follows it with '\n' bytes, which decoding ignores, and the
+// adapter's Decode removes them even if enc is strict, keeping the trailing
+// newline if enc adds one. EncodeToString adds no padding.
+func (enc *Encoding) Codec() BinaryTextCodec {
+	return codec{enc}
+}
+
+type codec struct {
+	*Encoding
+}
+
+func (c codec) Encode(dst, src []byte) {
+	size := c.EncodedLen(len(src))
+	n, err := c.Encoding.Encode(dst[:size], src)
+	if err != nil {
+		// As with base64, a dst that is too short is a programming error.
+		panic(err)
+	}
+	for i := n; i < size; i++ {
+		dst[i] = '\n'
+	}
+}
+
+func (c codec) Decode(dst, src []byte) (int, error) {
+	return c.Encoding.Decode(dst, c.trimPadding(src))
+}
+
+// trimPadding returns src without the '\n' bytes that Encode pads its output
+// with. Encoded output ends with at most one '\n', the trailing newline, so
+// that one is kept if the encoding adds it.
+func (c codec) trimPadding(src []byte) []byte {
+	n := len(src)
+	for n > 0 && src[n-1] == '\n' {
+		n--
+	}
+	if c.trailingNewline && n > 0 && n < len(src) {
+		n++
+	}
+	return src[:n]
+}
@@ -0,0 +1,65 @@
+package base91
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"testing"
+)
+
+var (
+	_ BinaryTextCodec = base64.StdEncoding
+	_ BinaryTextCodec = base32.StdEncoding
+	_ BinaryTextCodec = StdEncoding.Codec()
+)
+
+// roundTrip encodes and decodes data in the way that code written for base64
+// usually does.
+func roundTrip(c BinaryTextCodec, data []byte) ([]byte, error) {
+	buf := make([]byte, c.EncodedLen(len(data)))
+	c.Encode(buf, data)
+	dbuf := make([]byte, c.DecodedLen(len(buf)))
+	n, err := c.Decode(dbuf, buf)
+	return dbuf[:n], err
+}
+
+func TestCodec(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	for _, c := range []BinaryTextCodec{base64.StdEncoding, StdEncoding.Codec(), strict.Codec()} {
+		for n := 0; n < 100; n++ {
+			data := benchmarkData(n)
+			decoded, err := roundTrip(c, data)
+			if err != nil {
+				t.Fatalf("%T, n=%d: got decoding error: %v", c, n, err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("%T, n=%d: expected %x, got %x", c, n, data, decoded)
+			}
+
+			s := c.EncodeToString(data)
+			if decoded, err := c.DecodeString(s); err != nil || !bytes.Equal(decoded, data) {
+				t.Errorf("%T, n=%d: expected %x, got %x, %v", c, n, data, decoded, err)
+			}
+		}
+	}
+}
+
+func TestCodecPadding(t *testing.T) {
+	c := StdEncoding.Codec()
+	// Zero bytes encode to 14-bit groups, so the encoding is shorter than
+	// EncodedLen.
+	data := make([]byte, 13)
+	buf := make([]byte, c.EncodedLen(len(data)))
+	c.Encode(buf, data)
+
+	want := StdEncoding.EncodeToString(data)
+	if len(want) == len(buf) {
+		t.Fatalf("Expected the encoding to need padding")
+	}
+	if got := string(bytes.TrimRight(buf, "\n")); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := c.EncodeToString(data); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
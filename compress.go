@@ -0,0 +1,54 @@
+package base91
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// EncodeCompressed reads src until EOF, compresses it with gzip, and writes
+// the base91 encoding of the result to dst, wrapped and ended as configured
+// for enc. Compressing before encoding, rather than after, is the order that
+// shrinks the output, since encoded data does not compress well.
+func (enc *Encoding) EncodeCompressed(dst io.Writer, src io.Reader) error {
+	w := NewEncoder(enc, dst)
+	zw := gzip.NewWriter(w)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// DecodeCompressed reads base91 data produced by EncodeCompressed from src
+// until EOF, decodes and decompresses it, and writes the result to dst. If enc
+// has a maximum decoded length, it limits the decompressed data, so that a
+// small input cannot expand into a huge output; if the data would exceed it,
+// DecodeCompressed returns ErrTooLarge after writing that many bytes to dst.
+func (enc *Encoding) DecodeCompressed(dst io.Writer, src io.Reader) error {
+	zr, err := gzip.NewReader(NewDecoder(enc, src))
+	if err != nil {
+		return err
+	}
+	if enc.maxDecodedLen > 0 {
+		n, err := io.CopyN(dst, zr, int64(enc.maxDecodedLen))
+		if err == io.EOF && n < int64(enc.maxDecodedLen) {
+			err = nil
+		} else if err == nil {
+			// The limit was reached; see whether there is more.
+			var b [1]byte
+			if _, err = io.ReadFull(zr, b[:]); err == nil {
+				return ErrTooLarge
+			} else if err == io.EOF {
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+	} else if _, err := io.Copy(dst, zr); err != nil {
+		return err
+	}
+	return zr.Close()
+}
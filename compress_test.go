@@ -0,0 +1,100 @@
+package base91
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCompressed(t *testing.T) {
+	text := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 100))
+	cases := []struct {
+		enc  *Encoding
+		data []byte
+	}{
+		{StdEncoding, nil},
+		{StdEncoding, []byte("x")},
+		{StdEncoding, text},
+		{StdEncoding, benchmarkData(10000)},
+		{NewEncodingWithOptions(encodeStd, Wrap(76), TrailingNewline()), text},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.enc.EncodeCompressed(&buf, bytes.NewReader(tc.data)); err != nil {
+				t.Fatalf("Got encoding error: %v", err)
+			}
+
+			// The output is gzip data encoded with base91.
+			raw, err := tc.enc.DecodeString(buf.String())
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("Got gzip error: %v", err)
+			}
+			if got, _ := io.ReadAll(zr); !bytes.Equal(got, tc.data) {
+				t.Errorf("Expected %x, got %x", tc.data, got)
+			}
+
+			var decoded bytes.Buffer
+			if err := tc.enc.DecodeCompressed(&decoded, &buf); err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), tc.data) {
+				t.Errorf("Expected %x, got %x", tc.data, decoded.Bytes())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	StdEncoding.EncodeCompressed(&buf, bytes.NewReader(text))
+	if buf.Len() >= len(text)/10 {
+		t.Errorf("Expected compressed output shorter than %d bytes, got %d", len(text)/10, buf.Len())
+	}
+}
+
+func TestDecodeCompressedLimit(t *testing.T) {
+	data := make([]byte, 100000)
+	var buf bytes.Buffer
+	StdEncoding.EncodeCompressed(&buf, bytes.NewReader(data))
+	encoded := buf.String()
+
+	cases := []struct {
+		max int
+		err error
+	}{
+		{99999, ErrTooLarge},
+		{100000, nil},
+		{100001, nil},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			enc := NewEncodingWithOptions(encodeStd, MaxDecodedLen(tc.max))
+			var decoded bytes.Buffer
+			err := enc.DecodeCompressed(&decoded, strings.NewReader(encoded))
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if err == nil && !bytes.Equal(decoded.Bytes(), data) {
+				t.Errorf("Expected %d zero bytes, got %d bytes", len(data), decoded.Len())
+			}
+		})
+	}
+}
+
+func TestDecodeCompressedErrors(t *testing.T) {
+	for i, in := range []string{"", "dr/2s)uC", "dr-2"} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if err := StdEncoding.DecodeCompressed(io.Discard, strings.NewReader(in)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package base91
+
+// A Context is a kind of text into which encoded data might be embedded, for
+// use with CheckAlphabetSafeFor.
+type Context int
+
+const (
+	// JSONString is the inside of a JSON string literal.
+	JSONString Context = iota
+
+	// URLQuery is a value in a URL query string, such as one handled by
+	// net/url.Values. Characters outside the RFC 3986 query production and the
+	// form delimiters '&', '=', '+', and ';' require escaping.
+	URLQuery
+
+	// ShellDoubleQuoted is the inside of a double-quoted POSIX shell string.
+	ShellDoubleQuoted
+
+	// CSVField is an unquoted RFC 4180 CSV field.
+	CSVField
+
+	// MIMEHeader is the value of an email or other MIME header field, which
+	// may be folded onto several lines at any space or tab, and the body of a
+	// MIME part, where mail software may change whitespace.
+	MIMEHeader
+
+	// HTTPHeader is the value of an HTTP header field, which RFC 7230 limits to
+	// visible ASCII characters, spaces, and tabs, with leading and trailing
+	// whitespace removed by parsers.
+	HTTPHeader
+
+	// CookieValue is the value of an HTTP cookie, which RFC 6265 limits to
+	// visible ASCII characters other than '"', ',', ';', and '\'.
+	CookieValue
+
+	// XMLText is XML or HTML text, or an attribute value in double quotes,
+	// where '<', '>', '&', and '"' would need to be written as entities.
+	XMLText
+)
+
+func (ctx Context) String() string {
+	switch ctx {
+	case JSONString:
+		return "JSON string"
+	case URLQuery:
+		return "URL query"
+	case ShellDoubleQuoted:
+		return "shell double-quoted string"
+	case CSVField:
+		return "CSV field"
+	case MIMEHeader:
+		return "MIME header"
+	case HTTPHeader:
+		return "HTTP header"
+	case CookieValue:
+		return "cookie value"
+	case XMLText:
+		return "XML text"
+	}
+	return "unknown context"
+}
+
+// needsEscape reports whether c must be escaped in ctx.
+func (ctx Context) needsEscape(c byte) bool {
+	if c < 0x20 || c >= 0x7f {
+		// Control characters and non-ASCII bytes need escaping everywhere.
+		return true
+	}
+	switch ctx {
+	case JSONString:
+		return c == '"' || c == '\\'
+	case URLQuery:
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' {
+			return false
+		}
+		switch c {
+		case '-', '.', '_', '~', '!', '$', '\'', '(', ')', '*', ',', '/', ':', '?', '@':
+			return false
+		}
+		return true
+	case ShellDoubleQuoted:
+		return c == '"' || c == '\\' || c == '$' || c == '`'
+	case CSVField:
+		return c == ',' || c == '"'
+	case MIMEHeader, HTTPHeader:
+		return c == ' '
+	case CookieValue:
+		return c == ' ' || c == '"' || c == ',' || c == ';' || c == '\\'
+	case XMLText:
+		return c == '<' || c == '>' || c == '&' || c == '"'
+	}
+	return true
+}
+
+// CheckAlphabetSafeFor returns the characters in the alphabet of enc that
+// would need to be escaped if output from enc were embedded in ctx, in
+// alphabet order. If the result is empty, encoded data can be embedded in ctx
+// as is. This helps when designing a custom alphabet for a particular use.
+func CheckAlphabetSafeFor(enc *Encoding, ctx Context) string {
+	var chars []byte
+	for _, c := range enc.encode {
+		if ctx.needsEscape(c) {
+			chars = append(chars, c)
+		}
+	}
+	return string(chars)
+}
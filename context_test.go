@@ -0,0 +1,46 @@
+package base91
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCheckAlphabetSafeFor(t *testing.T) {
+	cases := []struct {
+		ctx  Context
+		want string
+	}{
+		{JSONString, "\""},
+		{URLQuery, "#%&+;<=>[]^`{|}\""},
+		{ShellDoubleQuoted, "$`\""},
+		{CSVField, ",\""},
+		{MIMEHeader, ""},
+		{HTTPHeader, ""},
+		{CookieValue, ",;\""},
+		{XMLText, "&<>\""},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := CheckAlphabetSafeFor(StdEncoding, tc.ctx); got != tc.want {
+				t.Errorf("%v: expected %q, got %q", tc.ctx, tc.want, got)
+			}
+		})
+	}
+
+	enc, err := StdEncoding.Clone(map[byte]byte{'"': '\''})
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if got := CheckAlphabetSafeFor(enc, JSONString); got != "" {
+		t.Errorf("Expected no unsafe characters, got %q", got)
+	}
+
+	enc, err = NewEncodingOmitting("\"'-\\")
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if got := CheckAlphabetSafeFor(enc, MIMEHeader); got != " " {
+		t.Errorf("Expected %q, got %q", " ", got)
+	}
+}
@@ -0,0 +1,64 @@
+package base91
+
+// RFC 6265 allows only 90 characters in a cookie value, so no base91 alphabet
+// can be used in cookies as it is. EncodeCookieValue instead uses
+// cookieEncoding, whose alphabet is the standard one with '"' and ',' replaced
+// by '\'' and '-', which leaves just ';' to avoid. It writes ';' as the two
+// characters "~_", and to keep that unambiguous writes '~' as "~~". Only those
+// 2 of the 91 symbols take two characters, so the output is about 2% longer
+// than plain base91, and still shorter than base64.
+
+// cookieEscape is the character that starts a two-character sequence in cookie
+// values.
+const cookieEscape = '~'
+
+var cookieEncoding = mustClone(StdEncoding, map[byte]byte{'"': '\'', ',': '-'})
+
+// EncodeCookieValue returns an encoding of src that contains only characters
+// allowed in a cookie value by RFC 6265, so that it can be stored in a cookie
+// without further escaping. It is base91 with a variant of the standard
+// alphabet in which two of the symbols take two characters each.
+func EncodeCookieValue(src []byte) string {
+	buf := make([]byte, maxEncodedLen(len(src)))
+	n, _ := cookieEncoding.encode91(buf, src)
+
+	out := make([]byte, 0, n+n/32)
+	for _, c := range buf[:n] {
+		switch c {
+		case ';':
+			out = append(out, cookieEscape, '_')
+		case cookieEscape:
+			out = append(out, cookieEscape, cookieEscape)
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
+
+// DecodeCookieValue returns the bytes represented by s, which was produced by
+// EncodeCookieValue. The offset in a CorruptInputError for an invalid symbol
+// counts each two-character sequence before it as one byte.
+func DecodeCookieValue(s string) ([]byte, error) {
+	src := []byte(s)
+	n := 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == ';' {
+			// ';' is in the underlying alphabet but is always escaped.
+			return nil, cookieEncoding.corruptInputError(src, i)
+		}
+		if c == cookieEscape {
+			if i+1 >= len(src) || (src[i+1] != '_' && src[i+1] != cookieEscape) {
+				return nil, cookieEncoding.corruptInputError(src, i)
+			}
+			if src[i+1] == '_' {
+				c = ';'
+			}
+			i++
+		}
+		src[n] = c
+		n++
+	}
+	return cookieEncoding.decodeToNew(src[:n], true)
+}
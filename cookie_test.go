@@ -0,0 +1,74 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCookieValue(t *testing.T) {
+	data := benchmarkData(10000)
+	for i, n := range []int{0, 1, 10, 100, 10000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			v := EncodeCookieValue(data[:n])
+			for j := 0; j < len(v); j++ {
+				if CookieValue.needsEscape(v[j]) {
+					t.Fatalf("Expected only cookie-safe characters, got %q at %d", v[j], j)
+				}
+			}
+
+			// The value survives a round trip through net/http, which checks
+			// cookie values against RFC 6265.
+			c := &http.Cookie{Name: "data", Value: v}
+			if err := c.Valid(); err != nil {
+				t.Fatalf("Got invalid cookie: %v", err)
+			}
+			req, _ := http.NewRequest("GET", "http://example.com/", nil)
+			req.AddCookie(c)
+			got, err := req.Cookie("data")
+			if err != nil {
+				t.Fatalf("Got cookie error: %v", err)
+			}
+
+			decoded, err := DecodeCookieValue(got.Value)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data[:n]) {
+				t.Errorf("Expected %x, got %x", data[:n], decoded)
+			}
+		})
+	}
+
+	if n, m := len(EncodeCookieValue(data)), len(StdEncoding.EncodeToString(data)); n > m*103/100 {
+		t.Errorf("Expected at most 3%% overhead over %d bytes, got %d bytes", m, n)
+	}
+}
+
+func TestDecodeCookieValueErrors(t *testing.T) {
+	cases := []struct {
+		in     string
+		offset int64
+	}{
+		{"dr;J", 2},
+		{"dr~", 2},
+		{"dr~J", 2},
+		{"dr\"J", 2},
+		{"dr,J", 2},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := DecodeCookieValue(tc.in)
+			var e CorruptInputError
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected CorruptInputError, got %v", err)
+			}
+			if e.Offset != tc.offset {
+				t.Errorf("Expected offset %d, got %d", tc.offset, e.Offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package base91
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotDataURI is returned by DecodeDataURI when its input is not a data URI
+// with base91 content.
+var ErrNotDataURI = errors.New("not a base91 data URI")
+
+const dataURIPrefix = "data:"
+
+// EncodeDataURI returns a data URI, in the manner of RFC 2397, holding data
+// and its media type, such as "image/png" or "text/plain;charset=utf-8":
+//
+//	data:<mediaType>;base91,<payload>
+//
+// The payload is the base91 encoding of data, without wrapping, with the
+// characters that may not appear as they are in a URI, such as '%', '#', and
+// '"', percent-encoded. Only 83 printable characters can appear in a URI as
+// they are, so every base91 alphabet has symbols that must be escaped; with
+// the standard alphabet, 10 of them are. On random data the escapes make the
+// payload about 20% longer than the plain encoding, and so longer than base64,
+// so base91 data URIs suit experiments and tools that unescape the payload
+// before storing it. mediaType may be empty, and must not contain ','.
+func (enc *Encoding) EncodeDataURI(mediaType string, data []byte) string {
+	if strings.IndexByte(mediaType, ',') >= 0 {
+		panic("data URI media type contains ','")
+	}
+
+	buf := make([]byte, maxEncodedLen(len(data)))
+	n, _ := enc.encode91(buf, data)
+
+	out := make([]byte, 0, len(dataURIPrefix)+len(mediaType)+len(";base91,")+n+n/4)
+	out = append(out, dataURIPrefix...)
+	out = append(out, mediaType...)
+	out = append(out, ";base91,"...)
+	out = appendPercentEncoded(out, buf[:n], uriNeedsEscape)
+	return string(out)
+}
+
+// DecodeDataURI returns the media type and data in s, a data URI produced by
+// EncodeDataURI. The "data:" scheme is matched without regard to case, and any
+// percent-encoded character in the payload is decoded before the payload
+// itself is. If s is not a data URI with base91 content, DecodeDataURI returns
+// ErrNotDataURI.
+func (enc *Encoding) DecodeDataURI(s string) (mediaType string, data []byte, err error) {
+	if len(s) < len(dataURIPrefix) || !strings.EqualFold(s[:len(dataURIPrefix)], dataURIPrefix) {
+		return "", nil, ErrNotDataURI
+	}
+	s = s[len(dataURIPrefix):]
+	comma := strings.IndexByte(s, ',')
+	if comma < 0 || !strings.HasSuffix(s[:comma], ";base91") {
+		return "", nil, ErrNotDataURI
+	}
+	mediaType = strings.TrimSuffix(s[:comma], ";base91")
+
+	payload, ok := percentDecode([]byte(s[comma+1:]))
+	if !ok {
+		return "", nil, ErrNotDataURI
+	}
+
+	data, err = enc.decodeToNew(payload, true)
+	if err != nil {
+		return "", nil, err
+	}
+	return mediaType, data, nil
+}
+
+// uriNeedsEscape reports whether c must be percent-encoded in the payload of a
+// data URI: it is neither an unreserved nor a reserved character of RFC 3986,
+// or it is '%' or '#', which would be taken for an escape or the start of a
+// fragment.
+func uriNeedsEscape(c byte) bool {
+	if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' {
+		return false
+	}
+	switch c {
+	case '-', '.', '_', '~', ':', '/', '?', '[', ']', '@', '!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=':
+		return false
+	}
+	return true
+}
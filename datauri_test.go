@@ -0,0 +1,93 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestEncodeDataURI(t *testing.T) {
+	cases := []struct {
+		mediaType string
+		data      string
+		want      string
+	}{
+		{"", "", "data:;base91,"},
+		{"text/plain", "foobar", "data:text/plain;base91,dr/2s)uC"},
+		{"text/plain;charset=utf-8", "hello", "data:text/plain;charset=utf-8;base91,TPwJh%3EA"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := StdEncoding.EncodeDataURI(tc.mediaType, []byte(tc.data)); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDataURIRoundTrip(t *testing.T) {
+	data := benchmarkData(1000)
+	for i, n := range []int{0, 1, 10, 1000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			s := StdEncoding.EncodeDataURI("image/png", data[:n])
+
+			// The result is a valid URI as it is.
+			u, err := url.Parse(s)
+			if err != nil {
+				t.Fatalf("Got URL parsing error: %v", err)
+			}
+			if u.Scheme != "data" || u.Fragment != "" {
+				t.Errorf("Expected a data URI with no fragment, got %#v", u)
+			}
+
+			mediaType, decoded, err := StdEncoding.DecodeDataURI(s)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if mediaType != "image/png" {
+				t.Errorf("Expected media type %q, got %q", "image/png", mediaType)
+			}
+			if !bytes.Equal(decoded, data[:n]) {
+				t.Errorf("Expected %x, got %x", data[:n], decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeDataURI(t *testing.T) {
+	cases := []struct {
+		in        string
+		mediaType string
+		data      string
+		err       error
+	}{
+		{"DATA:text/plain;base91,dr/2s)uC", "text/plain", "foobar", nil},
+		{"data:;base91,TPwJh%3eA", "", "hello", nil},
+		{"data:;base91,TPwJh>A", "", "hello", nil},
+		{"", "", "", ErrNotDataURI},
+		{"dat", "", "", ErrNotDataURI},
+		{"http://example.com/", "", "", ErrNotDataURI},
+		{"data:text/plain;base64,Zm9v", "", "", ErrNotDataURI},
+		{"data:text/plain;base91", "", "", ErrNotDataURI},
+		{"data:;base91,TPwJh%3", "", "", ErrNotDataURI},
+		{"data:;base91,TPwJh%3xA", "", "", ErrNotDataURI},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			mediaType, data, err := StdEncoding.DecodeDataURI(tc.in)
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if mediaType != tc.mediaType || string(data) != tc.data {
+				t.Errorf("Expected %q and %q, got %q and %q", tc.mediaType, tc.data, mediaType, data)
+			}
+		})
+	}
+
+	if _, _, err := StdEncoding.DecodeDataURI("data:;base91,A%2DA"); err == nil {
+		t.Error("Expected error for invalid payload, got nil")
+	}
+}
@@ -0,0 +1,72 @@
+package base91
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnknownDigest is returned by FormatDigest and ParseDigest when the
+// digest algorithm is not one that they know, or when ParseDigest is given a
+// string without an algorithm name.
+var ErrUnknownDigest = errors.New("unknown digest algorithm")
+
+// digestSizes maps the names of the digest algorithms that FormatDigest and
+// ParseDigest know to the size of their digests in bytes.
+var digestSizes = map[string]int{
+	"md5":    16,
+	"sha1":   20,
+	"sha224": 28,
+	"sha256": 32,
+	"sha384": 48,
+	"sha512": 64,
+}
+
+// FormatDigest returns sum, a digest computed with the hash algorithm alg, in
+// the form alg-<digest>, such as "sha256-" followed by the encoding of a
+// SHA-256 sum using StdEncoding, which does not contain '-'. The digest takes
+// 40 characters or fewer for SHA-256, against 64 in hexadecimal, which makes
+// the form suit manifests and lockfiles. alg is one of "md5", "sha1",
+// "sha224", "sha256", "sha384", and "sha512". FormatDigest returns
+// ErrUnknownDigest for any other alg, and ErrWrongLength if sum is not the
+// size of a digest computed with alg.
+func FormatDigest(alg string, sum []byte) (string, error) {
+	size, ok := digestSizes[alg]
+	if !ok {
+		return "", ErrUnknownDigest
+	}
+	if len(sum) != size {
+		return "", ErrWrongLength
+	}
+
+	buf := make([]byte, 0, len(alg)+1+maxEncodedLen(size))
+	buf = append(buf, alg...)
+	buf = append(buf, '-')
+	return string(StdEncoding.appendUnwrapped(buf, sum)), nil
+}
+
+// ParseDigest returns the algorithm and digest in s, a string produced by
+// FormatDigest. It returns ErrUnknownDigest if the algorithm is missing or
+// unknown, ErrWrongLength if the digest is not the size of one computed with
+// the algorithm, and a CorruptInputError if the digest is not exactly the
+// encoding that FormatDigest produces, so that equal digests always have equal
+// strings.
+func ParseDigest(s string) (alg string, sum []byte, err error) {
+	i := strings.IndexByte(s, '-')
+	if i < 0 {
+		return "", nil, ErrUnknownDigest
+	}
+	alg = s[:i]
+	size, ok := digestSizes[alg]
+	if !ok {
+		return "", nil, ErrUnknownDigest
+	}
+
+	sum = make([]byte, size)
+	if _, err := StdEncoding.decodeCanonical(sum, stringBytes(s[i+1:]), true); err != nil {
+		if e, ok := err.(CorruptInputError); ok {
+			err = newCorruptInputError(stringBytes(s), int(e.Offset)+i+1, e.Encoding)
+		}
+		return "", nil, err
+	}
+	return alg, sum, nil
+}
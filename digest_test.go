@@ -0,0 +1,103 @@
+package base91
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	sum := sha256.Sum256([]byte("hello"))
+	s, err := FormatDigest("sha256", sum[:])
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if want := "sha256-" + StdEncoding.EncodeToString(sum[:]); s != want {
+		t.Errorf("Expected %q, got %q", want, s)
+	}
+
+	alg, got, err := ParseDigest(s)
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if alg != "sha256" {
+		t.Errorf("Expected algorithm sha256, got %q", alg)
+	}
+	if !bytes.Equal(got, sum[:]) {
+		t.Errorf("Expected %v, got %v", sum, got)
+	}
+}
+
+func TestDigestSizes(t *testing.T) {
+	for alg, size := range digestSizes {
+		t.Run(alg, func(t *testing.T) {
+			sum := benchmarkData(size)
+			s, err := FormatDigest(alg, sum)
+			if err != nil {
+				t.Fatalf("Got error: %v", err)
+			}
+			gotAlg, got, err := ParseDigest(s)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if gotAlg != alg || !bytes.Equal(got, sum) {
+				t.Errorf("Expected %s %v, got %s %v", alg, sum, gotAlg, got)
+			}
+
+			if _, err := FormatDigest(alg, sum[1:]); err != ErrWrongLength {
+				t.Errorf("Expected ErrWrongLength for short digest, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFormatDigestUnknown(t *testing.T) {
+	if _, err := FormatDigest("crc32", make([]byte, 4)); err != ErrUnknownDigest {
+		t.Errorf("Expected ErrUnknownDigest, got %v", err)
+	}
+}
+
+func TestParseDigestInvalid(t *testing.T) {
+	sum := sha256.Sum256(nil)
+	valid, _ := FormatDigest("sha256", sum[:])
+	short, _ := FormatDigest("sha1", make([]byte, 20))
+
+	cases := []struct {
+		s      string
+		err    error
+		offset int64 // Offset of a CorruptInputError.
+	}{
+		{"", ErrUnknownDigest, 0},
+		{valid[len("sha256-"):], ErrUnknownDigest, 0},
+		{"SHA256" + valid[len("sha256"):], ErrUnknownDigest, 0},
+		{"sha256-" + short[len("sha1-"):], ErrWrongLength, 0},
+		{valid[:len(valid)-2], ErrWrongLength, 0},
+		{valid[:20] + "\n" + valid[20:], nil, 20},
+		{valid + " ", nil, int64(len(valid))},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, _, err := ParseDigest(tc.s)
+			if tc.err != nil {
+				if err != tc.err {
+					t.Errorf("Expected %v, got %v", tc.err, err)
+				}
+				return
+			}
+			var e CorruptInputError
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected CorruptInputError, got %v", err)
+			}
+			if e.Offset != tc.offset {
+				t.Errorf("Expected offset %d, got %d", tc.offset, e.Offset)
+			}
+			if !strings.Contains(tc.s, e.Context()) {
+				t.Errorf("Expected context from input, got %q", e.Context())
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package base91
+
+// DecodeFileMmap returns the bytes represented by the base91 data in the named
+// file. It is meant for tools that handle encoded files of many gigabytes. On
+// platforms that support it, the file is memory-mapped rather than read, so
+// that the only copy of the data made is the decoded result; elsewhere the
+// file is read into memory first. As with any memory-mapped file, the program
+// may crash if the file is truncated while it is being decoded.
+//
+// If the file contains invalid data, DecodeFileMmap returns the data decoded
+// before the problem along with the error, in the manner of DecodeString.
+func (enc *Encoding) DecodeFileMmap(path string) ([]byte, error) {
+	var dst []byte
+	err := mapFile(path, func(src []byte) error {
+		size, err := enc.decodeBufLen(src)
+		if err != nil {
+			return err
+		}
+		dst = make([]byte, size)
+		n, err := enc.Decode(dst, src)
+		dst = dst[:n]
+		return err
+	})
+	return dst, err
+}
+
+// EncodeFileMmap returns the base91 encoding of the contents of the named file.
+// Like DecodeFileMmap, it memory-maps the file where possible rather than
+// reading it.
+func (enc *Encoding) EncodeFileMmap(path string) ([]byte, error) {
+	var dst []byte
+	err := mapFile(path, func(src []byte) error {
+		dst = make([]byte, enc.EncodedLen(len(src)))
+		n, err := enc.Encode(dst, src)
+		dst = dst[:n]
+		return err
+	})
+	return dst, err
+}
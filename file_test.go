@@ -0,0 +1,56 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileMmap(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []int{0, 1, 1000, 100 << 10} {
+		data := benchmarkData(n)
+		raw := filepath.Join(dir, "raw")
+		if err := os.WriteFile(raw, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := StdEncoding.EncodeFileMmap(raw)
+		if err != nil {
+			t.Fatalf("Got encoding error: %v", err)
+		}
+		if want := StdEncoding.EncodeToString(data); string(encoded) != want {
+			t.Fatalf("Expected %q, got %q", want, encoded)
+		}
+
+		b91 := filepath.Join(dir, "b91")
+		if err := os.WriteFile(b91, encoded, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := StdEncoding.DecodeFileMmap(b91)
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Fatalf("Expected %x, got %x", data, decoded)
+		}
+	}
+}
+
+func TestFileMmapErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := StdEncoding.DecodeFileMmap(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected %v, got %v", os.ErrNotExist, err)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	if err := os.WriteFile(bad, []byte("dr/2s)uC-"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := StdEncoding.DecodeFileMmap(bad)
+	if !errors.Is(err, ErrCorruptInput) || string(got) != "foobar" {
+		t.Errorf("Expected %q and %v, got %q and %v", "foobar", ErrCorruptInput, got, err)
+	}
+}
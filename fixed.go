@@ -0,0 +1,66 @@
+package base91
+
+import "errors"
+
+// The functions in this file encode and decode the fixed-size values that are
+// most often encoded on their own: 16-byte values such as UUIDs, 32-byte values
+// such as SHA-256 digests and Ed25519 public keys, and 64-byte values such as
+// Ed25519 signatures. They are convenience wrappers around the general encoder
+// and decoder that take and return arrays, so they never allocate, but they are
+// no faster than Encode and Decode. Their output is never wrapped.
+
+// ErrWrongLength is returned by the fixed-size decoding functions, such as
+// Decode16, when the input does not decode to exactly the expected number of
+// bytes.
+var ErrWrongLength = errors.New("decoded data has the wrong length")
+
+// Encode16 returns the base91 encoding of src in the first n bytes of dst.
+func (enc *Encoding) Encode16(src [16]byte) (dst [20]byte, n int) {
+	n, _ = enc.encode91(dst[:], src[:])
+	return dst, n
+}
+
+// Encode32 returns the base91 encoding of src in the first n bytes of dst.
+func (enc *Encoding) Encode32(src [32]byte) (dst [40]byte, n int) {
+	n, _ = enc.encode91(dst[:], src[:])
+	return dst, n
+}
+
+// Encode64 returns the base91 encoding of src in the first n bytes of dst.
+func (enc *Encoding) Encode64(src [64]byte) (dst [79]byte, n int) {
+	n, _ = enc.encode91(dst[:], src[:])
+	return dst, n
+}
+
+// Decode16 returns the 16 bytes represented by src. If src is valid but does
+// not decode to exactly 16 bytes, Decode16 returns ErrWrongLength.
+func (enc *Encoding) Decode16(src []byte) ([16]byte, error) {
+	var dst [16]byte
+	err := enc.decodeFixed(dst[:], src)
+	return dst, err
+}
+
+// Decode32 returns the 32 bytes represented by src. If src is valid but does
+// not decode to exactly 32 bytes, Decode32 returns ErrWrongLength.
+func (enc *Encoding) Decode32(src []byte) ([32]byte, error) {
+	var dst [32]byte
+	err := enc.decodeFixed(dst[:], src)
+	return dst, err
+}
+
+// Decode64 returns the 64 bytes represented by src. If src is valid but does
+// not decode to exactly 64 bytes, Decode64 returns ErrWrongLength.
+func (enc *Encoding) Decode64(src []byte) ([64]byte, error) {
+	var dst [64]byte
+	err := enc.decodeFixed(dst[:], src)
+	return dst, err
+}
+
+// decodeFixed decodes src into dst, which it must fill exactly.
+func (enc *Encoding) decodeFixed(dst, src []byte) error {
+	n, err := enc.decodeUnwrapped(dst, src)
+	if err == ErrShortDst || (err == nil && n != len(dst)) {
+		return ErrWrongLength
+	}
+	return err
+}
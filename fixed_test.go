@@ -0,0 +1,121 @@
+package base91
+
+import "testing"
+
+func TestFixedSize(t *testing.T) {
+	data := benchmarkData(64)
+	var src16 [16]byte
+	var src32 [32]byte
+	var src64 [64]byte
+	copy(src16[:], data)
+	copy(src32[:], data)
+	copy(src64[:], data)
+
+	dst16, n := StdEncoding.Encode16(src16)
+	if got, want := string(dst16[:n]), StdEncoding.EncodeToString(src16[:]); got != want {
+		t.Errorf("Encode16: expected %q, got %q", want, got)
+	}
+	if got, err := StdEncoding.Decode16(dst16[:n]); err != nil || got != src16 {
+		t.Errorf("Decode16: expected %x, got %x (error: %v)", src16, got, err)
+	}
+
+	dst32, n := StdEncoding.Encode32(src32)
+	if got, want := string(dst32[:n]), StdEncoding.EncodeToString(src32[:]); got != want {
+		t.Errorf("Encode32: expected %q, got %q", want, got)
+	}
+	if got, err := StdEncoding.Decode32(dst32[:n]); err != nil || got != src32 {
+		t.Errorf("Decode32: expected %x, got %x (error: %v)", src32, got, err)
+	}
+
+	dst64, n := StdEncoding.Encode64(src64)
+	if got, want := string(dst64[:n]), StdEncoding.EncodeToString(src64[:]); got != want {
+		t.Errorf("Encode64: expected %q, got %q", want, got)
+	}
+	if got, err := StdEncoding.Decode64(dst64[:n]); err != nil || got != src64 {
+		t.Errorf("Decode64: expected %x, got %x (error: %v)", src64, got, err)
+	}
+
+	// The worst case fills the output array.
+	var ones [64]byte
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	if _, n := StdEncoding.Encode64(ones); n != len(dst64) {
+		t.Errorf("Expected worst-case encoding of %d bytes, got %d", len(dst64), n)
+	}
+}
+
+func TestDecodeFixedErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		err   error
+	}{
+		{"", ErrWrongLength},
+		{"dr/2s)uC", ErrWrongLength},
+		{StdEncoding.EncodeToString(make([]byte, 15)), ErrWrongLength},
+		{StdEncoding.EncodeToString(make([]byte, 17)), ErrWrongLength},
+		{StdEncoding.EncodeToString(make([]byte, 16)), nil},
+		{StdEncoding.EncodeToString(make([]byte, 16)) + "\n", nil},
+	}
+
+	for i, tc := range cases {
+		if _, err := StdEncoding.Decode16([]byte(tc.input)); err != tc.err {
+			t.Errorf("case_%d: expected %v, got %v", i, tc.err, err)
+		}
+	}
+
+	if _, err := StdEncoding.Decode16([]byte("dr/2-s)uC")); err == nil || err == ErrWrongLength {
+		t.Errorf("Expected CorruptInputError, got %v", err)
+	}
+}
+
+func TestFixedSizeAllocs(t *testing.T) {
+	var src [32]byte
+	dst, n := StdEncoding.Encode32(src)
+	encoded := dst[:n]
+
+	if n := testing.AllocsPerRun(100, func() { StdEncoding.Encode32(src) }); n != 0 {
+		t.Errorf("Encode32: expected no allocations, got %v", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { StdEncoding.Decode32(encoded) }); n != 0 {
+		t.Errorf("Decode32: expected no allocations, got %v", n)
+	}
+}
+
+func BenchmarkEncode16(b *testing.B) {
+	var src [16]byte
+	copy(src[:], benchmarkData(16))
+	b.SetBytes(16)
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Encode16(src)
+	}
+}
+
+func BenchmarkEncode32(b *testing.B) {
+	var src [32]byte
+	copy(src[:], benchmarkData(32))
+	b.SetBytes(32)
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Encode32(src)
+	}
+}
+
+func BenchmarkEncode64(b *testing.B) {
+	var src [64]byte
+	copy(src[:], benchmarkData(64))
+	b.SetBytes(64)
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Encode64(src)
+	}
+}
+
+func BenchmarkDecode32(b *testing.B) {
+	var src [32]byte
+	copy(src[:], benchmarkData(32))
+	dst, n := StdEncoding.Encode32(src)
+	encoded := dst[:n]
+	b.SetBytes(int64(n))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.Decode32(encoded)
+	}
+}
@@ -0,0 +1,21 @@
+package base91
+
+// String returns the base91 encoding of b in the standard alphabet. With Set,
+// it makes *Bytes a flag.Value, so that a program can take binary keys or
+// nonces as base91 command-line arguments:
+//
+//	var key base91.Bytes
+//	flag.Var(&key, "key", "encryption key, base91-encoded")
+func (b Bytes) String() string {
+	return StdEncoding.EncodeToString(b)
+}
+
+// Set sets *b to the data encoded in s, as flag.Value requires.
+func (b *Bytes) Set(s string) error {
+	decoded, err := StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
@@ -0,0 +1,39 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+)
+
+var _ flag.Value = (*Bytes)(nil)
+
+func TestBytesFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var key Bytes
+	fs.Var(&key, "key", "key")
+
+	if err := fs.Parse([]string{"-key", "dr/2s)uC"}); err != nil {
+		t.Fatalf("Got parsing error: %v", err)
+	}
+	if !bytes.Equal(key, []byte("foobar")) {
+		t.Errorf("Expected %q, got %q", "foobar", []byte(key))
+	}
+	if got := fs.Lookup("key").Value.String(); got != "dr/2s)uC" {
+		t.Errorf("Expected %q, got %q", "dr/2s)uC", got)
+	}
+	if got := fmt.Sprint(key); got != "dr/2s)uC" {
+		t.Errorf("Expected %q, got %q", "dr/2s)uC", got)
+	}
+
+	if err := fs.Parse([]string{"-key", "ab c"}); err == nil {
+		t.Errorf("Expected error, got none")
+	}
+	if err := key.Set("ab c"); !errors.Is(err, ErrCorruptInput) {
+		t.Errorf("Expected ErrCorruptInput, got %v", err)
+	}
+}
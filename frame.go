@@ -0,0 +1,323 @@
+package base91
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strconv"
+)
+
+// The framed container format splits data into frames that are encoded
+// independently, one per line, so that a stream can be appended to, decoded in
+// parallel, or recovered after damage, none of which plain base91 allows. A
+// stream is a header line followed by any number of frame lines:
+//
+//	base91-frames/1 crc32
+//	6 9ef61f95 dr/2s)uC
+//	3 78240498 [D*K
+//
+// The header names the format and version and, if frames carry checksums,
+// "crc32". Each frame line holds the length in decimal of the data in the
+// frame, the CRC-32 (IEEE) of that data in 8 hexadecimal digits if checksums
+// are enabled, and the base91 encoding of the data, separated by single
+// spaces. Line breaks are '\n', and each frame is encoded from a fresh state,
+// without wrapping.
+
+const (
+	frameMagic    = "base91-frames/1"
+	frameChecksum = " crc32"
+)
+
+// ErrBadFrame is returned when reading framed data that does not follow the
+// format, such as a frame whose length does not match its data.
+var ErrBadFrame = errors.New("malformed base91 frame")
+
+// ErrChecksum is returned when data, such as a frame or an armored block, does
+// not match its checksum.
+var ErrChecksum = errors.New("base91 checksum mismatch")
+
+// A FrameWriter writes data in the framed container format. Data written to it
+// is buffered until a whole frame is available; Flush writes any buffered data
+// as a shorter frame, and Close does the same.
+type FrameWriter struct {
+	enc         *Encoding
+	w           io.Writer
+	size        int
+	checksum    bool
+	buf         []byte // Data for the next frame.
+	line        []byte // Scratch space for building frame lines.
+	wroteHeader bool
+	err         error
+}
+
+// NewFrameWriter returns a FrameWriter that writes data encoded with enc to w,
+// in frames of frameSize bytes, each with a CRC-32 checksum if checksum is
+// true. frameSize must be positive.
+func NewFrameWriter(enc *Encoding, w io.Writer, frameSize int, checksum bool) *FrameWriter {
+	if frameSize <= 0 {
+		panic("frame size is not positive")
+	}
+	return &FrameWriter{
+		enc:      enc,
+		w:        w,
+		size:     frameSize,
+		checksum: checksum,
+		buf:      make([]byte, 0, frameSize),
+	}
+}
+
+// Write writes p to the stream, writing a frame to the underlying writer each
+// time a whole frame of data is available.
+func (fw *FrameWriter) Write(p []byte) (int, error) {
+	if fw.err != nil {
+		return 0, fw.err
+	}
+
+	n := 0
+	for len(p) > 0 {
+		k := fw.size - len(fw.buf)
+		if k > len(p) {
+			k = len(p)
+		}
+		fw.buf = append(fw.buf, p[:k]...)
+		n += k
+		p = p[k:]
+
+		if len(fw.buf) == fw.size {
+			if err := fw.writeFrame(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Flush writes any buffered data as a frame, so that everything written so far
+// can be read back from the underlying writer. A stream that is flushed after
+// each record can be appended to and read while it is still being written.
+func (fw *FrameWriter) Flush() error {
+	if fw.err != nil {
+		return fw.err
+	}
+	if len(fw.buf) > 0 || !fw.wroteHeader {
+		return fw.writeFrame()
+	}
+	return nil
+}
+
+// Close flushes any buffered data. It does not close the underlying writer.
+// A stream to which nothing was written still gets a header, so that it can be
+// read back as an empty stream.
+func (fw *FrameWriter) Close() error {
+	return fw.Flush()
+}
+
+// writeFrame writes the buffered data as a frame, preceded by the header if
+// this is the first frame. If there is no data, only the header is written.
+func (fw *FrameWriter) writeFrame() error {
+	line := fw.line[:0]
+	if !fw.wroteHeader {
+		line = append(line, frameMagic...)
+		if fw.checksum {
+			line = append(line, frameChecksum...)
+		}
+		line = append(line, '\n')
+	}
+	if len(fw.buf) > 0 {
+		line = appendFrame(line, fw.enc, fw.buf, fw.checksum)
+	}
+	fw.line = line
+
+	if _, err := fw.w.Write(line); err != nil {
+		fw.err = err
+		return err
+	}
+	fw.wroteHeader = true
+	fw.buf = fw.buf[:0]
+	return nil
+}
+
+// appendFrame appends to line the frame line, including its line break, for
+// data encoded with enc, with a checksum if checksum is true.
+func appendFrame(line []byte, enc *Encoding, data []byte, checksum bool) []byte {
+	line = strconv.AppendInt(line, int64(len(data)), 10)
+	line = append(line, ' ')
+	if checksum {
+		line = appendHex32(line, crc32.ChecksumIEEE(data))
+		line = append(line, ' ')
+	}
+	start := len(line)
+	line = append(line, make([]byte, maxEncodedLen(len(data)))...)
+	n, _ := enc.encode91(line[start:], data)
+	return append(line[:start+n], '\n')
+}
+
+// appendHex32 appends v to b as 8 lowercase hexadecimal digits.
+func appendHex32(b []byte, v uint32) []byte {
+	for shift := 28; shift >= 0; shift -= 4 {
+		b = append(b, hexDigits[v>>uint(shift)&15])
+	}
+	return b
+}
+
+// A FrameReader reads data in the framed container format.
+type FrameReader struct {
+	enc        *Encoding
+	r          *bufio.Reader
+	checksum   bool
+	readHeader bool
+	frame      []byte // Decoded data of the current frame not yet read.
+	err        error
+}
+
+// NewFrameReader returns a FrameReader that reads framed data encoded with enc
+// from r.
+func NewFrameReader(enc *Encoding, r io.Reader) *FrameReader {
+	return &FrameReader{enc: enc, r: bufio.NewReader(r)}
+}
+
+// Read reads decoded data from the stream, across frame boundaries. It stops at
+// the first error, including a damaged frame; use ReadFrame to skip damaged
+// frames instead.
+func (fr *FrameReader) Read(p []byte) (int, error) {
+	for len(fr.frame) == 0 {
+		if fr.err != nil {
+			return 0, fr.err
+		}
+		frame, err := fr.ReadFrame()
+		if err != nil {
+			fr.err = err
+			return 0, err
+		}
+		fr.frame = frame
+	}
+	n := copy(p, fr.frame)
+	fr.frame = fr.frame[n:]
+	return n, nil
+}
+
+// ReadFrame returns the decoded data of the next frame in the stream, or io.EOF
+// at the end of the stream. If a frame is damaged, ReadFrame returns an error
+// for it, such as ErrChecksum, ErrBadFrame, or a CorruptInputError, and the
+// next call moves on to the following frame, so that a reader can recover the
+// rest of a stream. A missing or unrecognized header is not recoverable.
+// ReadFrame must not be mixed with Read.
+func (fr *FrameReader) ReadFrame() ([]byte, error) {
+	if !fr.readHeader {
+		checksum, _, err := readFrameHeader(fr.r)
+		if err != nil {
+			return nil, err
+		}
+		fr.checksum = checksum
+		fr.readHeader = true
+	}
+
+	line, _, err := readFrameLine(fr.r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFrame(fr.enc, line, fr.checksum)
+}
+
+// readFrameHeader reads the header line of a framed stream from r. It reports
+// whether frames carry checksums, and the length of the line.
+func readFrameHeader(r *bufio.Reader) (checksum bool, n int, err error) {
+	line, n, err := readFrameLine(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	switch string(line) {
+	case frameMagic:
+		return false, n, nil
+	case frameMagic + frameChecksum:
+		return true, n, nil
+	}
+	return false, 0, ErrBadFrame
+}
+
+// readFrameLine reads the next line from r. It returns the line without its
+// line break, and the length of the line with it.
+func readFrameLine(r *bufio.Reader) ([]byte, int, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// The line is longer than the buffer, so collect it in pieces.
+		long := append([]byte(nil), line...)
+		for err == bufio.ErrBufferFull {
+			line, err = r.ReadSlice('\n')
+			long = append(long, line...)
+		}
+		line = long
+	}
+	if err == io.EOF && len(line) > 0 {
+		// The last line has no line break, as after a truncated write.
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return trimFrameLine(line), len(line), nil
+}
+
+// trimFrameLine returns line without its line break.
+func trimFrameLine(line []byte) []byte {
+	line = bytes.TrimSuffix(line, newline)
+	return bytes.TrimSuffix(line, []byte{'\r'})
+}
+
+// parseFrameLength parses the length field at the start of a frame line. It
+// returns the length and the rest of the line.
+func parseFrameLength(line []byte) (int, []byte, error) {
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return 0, nil, ErrBadFrame
+	}
+	length, err := strconv.Atoi(string(line[:i]))
+	if err != nil || length < 0 {
+		return 0, nil, ErrBadFrame
+	}
+	return length, line[i+1:], nil
+}
+
+// decodeFrame parses and decodes a frame line, without its line break, from a
+// stream encoded with enc, verifying its checksum if it has one.
+func decodeFrame(enc *Encoding, line []byte, checksum bool) ([]byte, error) {
+	length, line, err := parseFrameLength(line)
+	if err != nil {
+		return nil, err
+	}
+	var sum uint64
+	if checksum {
+		if len(line) < 9 || line[8] != ' ' {
+			return nil, ErrBadFrame
+		}
+		if sum, err = strconv.ParseUint(string(line[:8]), 16, 32); err != nil {
+			return nil, ErrBadFrame
+		}
+		line = line[9:]
+	}
+
+	if enc.DecodedLen(len(line)) < length {
+		return nil, ErrBadFrame
+	}
+	if enc.maxDecodedLen > 0 && length > enc.maxDecodedLen {
+		return nil, ErrTooLarge
+	}
+	data := make([]byte, enc.DecodedLen(len(line)))
+	n, err := enc.decodeUnwrapped(data, line)
+	if err != nil {
+		return nil, err
+	}
+	if n != length {
+		return nil, ErrBadFrame
+	}
+	data = data[:n]
+	if checksum && crc32.ChecksumIEEE(data) != uint32(sum) {
+		return nil, ErrChecksum
+	}
+	return data, nil
+}
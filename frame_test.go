@@ -0,0 +1,182 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFrameWriter(t *testing.T) {
+	var buf bytes.Buffer
+	fw := NewFrameWriter(StdEncoding, &buf, 6, true)
+	if _, err := fw.Write([]byte("foob")); err != nil {
+		t.Fatalf("Got writing error: %v", err)
+	}
+	if _, err := fw.Write([]byte("arbaz")); err != nil {
+		t.Fatalf("Got writing error: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("Got closing error: %v", err)
+	}
+
+	want := "base91-frames/1 crc32\n6 9ef61f95 dr/2s)uC\n3 78240498 [D*K\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	data := benchmarkData(1000)
+	cases := []struct {
+		n         int
+		frameSize int
+		checksum  bool
+	}{
+		{0, 10, false},
+		{0, 10, true},
+		{1, 10, true},
+		{10, 10, false},
+		{11, 10, true},
+		{1000, 64, false},
+		{1000, 64, true},
+		{1000, 5000, true},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			fw := NewFrameWriter(StdEncoding, &buf, tc.frameSize, tc.checksum)
+			if _, err := fw.Write(data[:tc.n]); err != nil {
+				t.Fatalf("Got writing error: %v", err)
+			}
+			if err := fw.Close(); err != nil {
+				t.Fatalf("Got closing error: %v", err)
+			}
+
+			decoded, err := io.ReadAll(NewFrameReader(StdEncoding, &buf))
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data[:tc.n]) {
+				t.Errorf("Expected %x, got %x", data[:tc.n], decoded)
+			}
+		})
+	}
+}
+
+func TestFrameWriterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	fw := NewFrameWriter(StdEncoding, &buf, 100, false)
+	for _, rec := range []string{"first", "second", "third"} {
+		fw.Write([]byte(rec))
+		if err := fw.Flush(); err != nil {
+			t.Fatalf("Got flushing error: %v", err)
+		}
+	}
+
+	fr := NewFrameReader(StdEncoding, &buf)
+	for _, want := range []string{"first", "second", "third"} {
+		got, err := fr.ReadFrame()
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+	if _, err := fr.ReadFrame(); err != io.EOF {
+		t.Errorf("Expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestFrameReaderRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	fw := NewFrameWriter(StdEncoding, &buf, 3, true)
+	fw.Write([]byte("foobarbazqux"))
+	fw.Close()
+
+	lines := strings.Split(buf.String(), "\n")
+	lines[2] = strings.Replace(lines[2], "3 ", "4 ", 1) // Wrong length.
+	lines[3] = lines[3][:len(lines[3])-1] + "A"         // Wrong checksum.
+	damaged := strings.Join(lines, "\n")
+
+	fr := NewFrameReader(StdEncoding, strings.NewReader(damaged))
+	cases := []struct {
+		data string
+		err  error
+	}{
+		{"foo", nil},
+		{"", ErrBadFrame},
+		{"", ErrChecksum},
+		{"qux", nil},
+		{"", io.EOF},
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			got, err := fr.ReadFrame()
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if string(got) != tc.data {
+				t.Errorf("Expected %q, got %q", tc.data, got)
+			}
+		})
+	}
+
+	_, err := io.ReadAll(NewFrameReader(StdEncoding, strings.NewReader(damaged)))
+	if err != ErrBadFrame {
+		t.Errorf("Expected %v, got %v", ErrBadFrame, err)
+	}
+}
+
+func TestFrameReaderErrors(t *testing.T) {
+	cases := []struct {
+		in  string
+		err error
+	}{
+		{"", io.ErrUnexpectedEOF},
+		{"base91-frames/2\n", ErrBadFrame},
+		{"base91-frames/1\n3 dr.J", io.ErrUnexpectedEOF},
+		{"base91-frames/1\nx dr.J\n", ErrBadFrame},
+		{"base91-frames/1\n-1 dr.J\n", ErrBadFrame},
+		{"base91-frames/1\n30 dr.J\n", ErrBadFrame},
+		{"base91-frames/1 crc32\n3 dr.J\n", ErrBadFrame},
+		{"base91-frames/1 crc32\n3 8c7365 dr.J\n", ErrBadFrame},
+		{"base91-frames/1 crc32\n3 8c73652x dr.J\n", ErrBadFrame},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := io.ReadAll(NewFrameReader(StdEncoding, strings.NewReader(tc.in)))
+			if err != tc.err {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	in := "base91-frames/1\n3 d-r.J\n"
+	_, err := io.ReadAll(NewFrameReader(StdEncoding, strings.NewReader(in)))
+	var e CorruptInputError
+	if !errors.As(err, &e) {
+		t.Errorf("Expected CorruptInputError, got %v", err)
+	}
+}
+
+func TestFrameReaderLongLine(t *testing.T) {
+	data := benchmarkData(20000)
+	var buf bytes.Buffer
+	fw := NewFrameWriter(StdEncoding, &buf, len(data), false)
+	fw.Write(data)
+	fw.Close()
+
+	decoded, err := io.ReadAll(NewFrameReader(StdEncoding, &buf))
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Expected %x, got %x", data, decoded)
+	}
+}
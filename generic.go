@@ -0,0 +1,28 @@
+package base91
+
+import "unsafe"
+
+// EncodeAny returns the base91 encoding of src, which may be a string or a byte
+// slice, or any type based on either. It saves callers whose payloads are held
+// in strings or in named types a conversion, and the copy that comes with
+// converting a string to a byte slice.
+func EncodeAny[T ~string | ~[]byte](enc *Encoding, src T) string {
+	return enc.EncodeToString(bytesOf(src))
+}
+
+// DecodeAny returns the bytes represented by the base91 data in src, which may
+// be a string or a byte slice, or any type based on either, as DecodeString
+// does.
+func DecodeAny[T ~string | ~[]byte](enc *Encoding, src T) ([]byte, error) {
+	return enc.decodeToNew(bytesOf(src), false)
+}
+
+// bytesOf returns a byte slice that shares its memory with src. If src is a
+// string, the slice must never be written to.
+func bytesOf[T ~string | ~[]byte](src T) []byte {
+	// Only strings are the size of a string header; slices are larger.
+	if unsafe.Sizeof(src) == unsafe.Sizeof("") {
+		return stringBytes(*(*string)(unsafe.Pointer(&src)))
+	}
+	return *(*[]byte)(unsafe.Pointer(&src))
+}
@@ -0,0 +1,52 @@
+package base91
+
+import (
+	"bytes"
+	"testing"
+)
+
+type token string
+
+type payload []byte
+
+func TestEncodeAny(t *testing.T) {
+	if got := EncodeAny(StdEncoding, "foobar"); got != "dr/2s)uC" {
+		t.Errorf("string: expected %q, got %q", "dr/2s)uC", got)
+	}
+	if got := EncodeAny(StdEncoding, token("foobar")); got != "dr/2s)uC" {
+		t.Errorf("token: expected %q, got %q", "dr/2s)uC", got)
+	}
+	if got := EncodeAny(StdEncoding, []byte("foobar")); got != "dr/2s)uC" {
+		t.Errorf("[]byte: expected %q, got %q", "dr/2s)uC", got)
+	}
+	if got := EncodeAny(StdEncoding, payload("foobar")); got != "dr/2s)uC" {
+		t.Errorf("payload: expected %q, got %q", "dr/2s)uC", got)
+	}
+	if got := EncodeAny(StdEncoding, payload(nil)); got != "" {
+		t.Errorf("nil: expected %q, got %q", "", got)
+	}
+
+	s := token("foobar")
+	if n := testing.AllocsPerRun(100, func() { EncodeAny(StdEncoding, s) }); n != 1 {
+		t.Errorf("Expected 1 allocation, got %v", n)
+	}
+}
+
+func TestDecodeAny(t *testing.T) {
+	want := []byte("foobar")
+	if got, err := DecodeAny(StdEncoding, "dr/2s)uC"); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("string: expected %q, got %q (error: %v)", want, got, err)
+	}
+	if got, err := DecodeAny(StdEncoding, token("dr/2s)uC")); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("token: expected %q, got %q (error: %v)", want, got, err)
+	}
+	if got, err := DecodeAny(StdEncoding, []byte("dr/2s)uC")); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("[]byte: expected %q, got %q (error: %v)", want, got, err)
+	}
+	if got, err := DecodeAny(StdEncoding, payload("dr/2s)uC")); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("payload: expected %q, got %q (error: %v)", want, got, err)
+	}
+	if _, err := DecodeAny(StdEncoding, token("dr/2-s)uC")); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -1 +1,3 @@
 module github.com/mtraver/base91
+
+go 1.18
@@ -0,0 +1,35 @@
+package base91
+
+// EncodeHTTPHeader returns the base91 encoding of src as a value that can be
+// used as it is in an HTTP header field. If the alphabet of enc contains
+// characters that RFC 7230 does not allow in a field value, as reported by
+// CheckAlphabetSafeFor for HTTPHeader, the standard alphabet, which is safe,
+// is used instead. The output is never wrapped.
+func (enc *Encoding) EncodeHTTPHeader(src []byte) string {
+	enc = enc.headerEncoding()
+	buf := make([]byte, maxEncodedLen(len(src)))
+	n, _ := enc.encode91(buf, src)
+	return string(buf[:n])
+}
+
+// DecodeHTTPHeader returns the bytes represented by s, a header field value
+// produced by EncodeHTTPHeader with an Encoding with the same alphabet. Spaces
+// and tabs around s, which HTTP allows, are ignored.
+func (enc *Encoding) DecodeHTTPHeader(s string) ([]byte, error) {
+	for len(s) > 0 && (s[0] == ' ' || s[0] == '\t') {
+		s = s[1:]
+	}
+	for len(s) > 0 && (s[len(s)-1] == ' ' || s[len(s)-1] == '\t') {
+		s = s[:len(s)-1]
+	}
+	return enc.headerEncoding().decodeToNew(stringBytes(s), true)
+}
+
+// headerEncoding returns the Encoding that EncodeHTTPHeader and
+// DecodeHTTPHeader use in place of enc.
+func (enc *Encoding) headerEncoding() *Encoding {
+	if CheckAlphabetSafeFor(enc, HTTPHeader) != "" {
+		return StdEncoding
+	}
+	return enc
+}
@@ -0,0 +1,60 @@
+package base91
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"testing"
+)
+
+func TestHTTPHeader(t *testing.T) {
+	spaced, err := NewEncodingOmitting("\"'-\\")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := benchmarkData(1000)
+	cases := []struct {
+		enc *Encoding
+		n   int
+		std bool // Whether the standard alphabet is used instead of enc.
+	}{
+		{StdEncoding, 0, true},
+		{StdEncoding, 1000, true},
+		{NewEncodingWithOptions(encodeStd, Wrap(10)), 1000, false},
+		{spaced, 1000, true},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			v := tc.enc.EncodeHTTPHeader(data[:tc.n])
+			if tc.std && v != StdEncoding.EncodeToString(data[:tc.n]) {
+				t.Errorf("Expected %q, got %q", StdEncoding.EncodeToString(data[:tc.n]), v)
+			}
+
+			// The value survives a round trip through an HTTP header.
+			var buf bytes.Buffer
+			h := http.Header{}
+			h.Set("X-Data", v)
+			h.Write(&buf)
+			buf.WriteString("\r\n")
+			parsed, err := textproto.NewReader(bufio.NewReader(&buf)).ReadMIMEHeader()
+			if err != nil {
+				t.Fatalf("Got header parsing error: %v", err)
+			}
+
+			decoded, err := tc.enc.DecodeHTTPHeader(parsed.Get("X-Data"))
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data[:tc.n]) {
+				t.Errorf("Expected %x, got %x", data[:tc.n], decoded)
+			}
+		})
+	}
+
+	if got, err := StdEncoding.DecodeHTTPHeader(" \tdr/2s)uC\t "); err != nil || string(got) != "foobar" {
+		t.Errorf("Expected %q, got %q and error %v", "foobar", got, err)
+	}
+}
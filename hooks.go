@@ -0,0 +1,130 @@
+package base91
+
+import "io"
+
+// StreamHooks holds optional callbacks through which a stream encoder or
+// decoder reports what it does, so that a service can feed its metrics system
+// without wrapping every reader and writer itself. Any of the callbacks may be
+// nil. They are called synchronously, from the goroutine using the stream, so
+// they should be quick, such as incrementing a counter.
+type StreamHooks struct {
+	// BytesIn is called with the number of bytes that the stream takes in:
+	// those written to an encoder, or read by a decoder from its underlying
+	// reader.
+	BytesIn func(n int)
+
+	// BytesOut is called with the number of bytes that the stream puts out:
+	// those written by an encoder to its underlying writer, or returned by a
+	// decoder's Read.
+	BytesOut func(n int)
+
+	// Error is called once, with the first error that the stream returns,
+	// other than io.EOF at the end of a decoder's input.
+	Error func(err error)
+}
+
+// InstrumentEncoder installs hooks on w, a stream encoder returned by
+// NewEncoder, NewEncoderWithHeader, or NewMIMEEncoder, and returns the
+// instrumented encoder, which must be used in place of w. It must be called
+// before anything is written to w. It panics if w is not a stream encoder from
+// this package.
+func InstrumentEncoder(w io.WriteCloser, hooks StreamHooks) io.WriteCloser {
+	s, ok := w.(*streamEncoder)
+	if !ok {
+		panic("writer is not a base91 stream encoder")
+	}
+	if hooks.BytesOut != nil {
+		s.w = &hookWriter{w: s.w, f: hooks.BytesOut}
+	}
+	return &hookedEncoder{s: s, hooks: hooks}
+}
+
+// InstrumentDecoder installs hooks on r, a stream decoder returned by
+// NewDecoder, NewDecoderWithHeader, or NewMIMEDecoder, and returns the
+// instrumented decoder, which must be used in place of r. It must be called
+// before anything is read from r. It panics if r is not a stream decoder from
+// this package.
+func InstrumentDecoder(r io.Reader, hooks StreamHooks) io.Reader {
+	d, ok := r.(*streamDecoder)
+	if !ok {
+		panic("reader is not a base91 stream decoder")
+	}
+	if hooks.BytesIn != nil {
+		d.r = &hookReader{r: d.r, f: hooks.BytesIn}
+	}
+	return &hookedDecoder{d: d, hooks: hooks}
+}
+
+type hookedEncoder struct {
+	s        *streamEncoder
+	hooks    StreamHooks
+	reported bool
+}
+
+func (e *hookedEncoder) Write(p []byte) (int, error) {
+	n, err := e.s.Write(p)
+	if n > 0 && e.hooks.BytesIn != nil {
+		e.hooks.BytesIn(n)
+	}
+	e.report(err)
+	return n, err
+}
+
+func (e *hookedEncoder) Close() error {
+	err := e.s.Close()
+	e.report(err)
+	return err
+}
+
+func (e *hookedEncoder) report(err error) {
+	if err != nil && !e.reported && e.hooks.Error != nil {
+		e.reported = true
+		e.hooks.Error(err)
+	}
+}
+
+type hookedDecoder struct {
+	d        *streamDecoder
+	hooks    StreamHooks
+	reported bool
+}
+
+func (d *hookedDecoder) Read(p []byte) (int, error) {
+	n, err := d.d.Read(p)
+	if n > 0 && d.hooks.BytesOut != nil {
+		d.hooks.BytesOut(n)
+	}
+	if err != nil && err != io.EOF && !d.reported && d.hooks.Error != nil {
+		d.reported = true
+		d.hooks.Error(err)
+	}
+	return n, err
+}
+
+// hookWriter calls f with the number of bytes written to w.
+type hookWriter struct {
+	w io.Writer
+	f func(n int)
+}
+
+func (h *hookWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	if n > 0 {
+		h.f(n)
+	}
+	return n, err
+}
+
+// hookReader calls f with the number of bytes read from r.
+type hookReader struct {
+	r io.Reader
+	f func(n int)
+}
+
+func (h *hookReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	if n > 0 {
+		h.f(n)
+	}
+	return n, err
+}
@@ -0,0 +1,80 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type streamCounts struct {
+	in, out int
+	errs    []error
+}
+
+func (c *streamCounts) hooks() StreamHooks {
+	return StreamHooks{
+		BytesIn:  func(n int) { c.in += n },
+		BytesOut: func(n int) { c.out += n },
+		Error:    func(err error) { c.errs = append(c.errs, err) },
+	}
+}
+
+func TestInstrumentEncoder(t *testing.T) {
+	data := benchmarkData(5000)
+	var buf bytes.Buffer
+	var c streamCounts
+	w := InstrumentEncoder(NewEncoder(StdEncoding, &buf), c.hooks())
+	w.Write(data[:1000])
+	w.Write(data[1000:])
+	if err := w.Close(); err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+
+	if c.in != len(data) || c.out != buf.Len() || len(c.errs) != 0 {
+		t.Errorf("Expected %d in, %d out, no errors, got %d, %d, %v", len(data), buf.Len(), c.in, c.out, c.errs)
+	}
+
+	// Writes after Close fail, and the error is reported once.
+	w.Write(data)
+	w.Write(data)
+	if len(c.errs) != 1 {
+		t.Errorf("Expected 1 error, got %v", c.errs)
+	}
+}
+
+func TestInstrumentDecoder(t *testing.T) {
+	data := benchmarkData(5000)
+	encoded := StdEncoding.EncodeToString(data)
+
+	var c streamCounts
+	r := InstrumentDecoder(NewDecoder(StdEncoding, strings.NewReader(encoded)), c.hooks())
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Expected %x, got %x", data, decoded)
+	}
+	if c.in != len(encoded) || c.out != len(data) || len(c.errs) != 0 {
+		t.Errorf("Expected %d in, %d out, no errors, got %d, %d, %v", len(encoded), len(data), c.in, c.out, c.errs)
+	}
+
+	c = streamCounts{}
+	r = InstrumentDecoder(NewDecoder(StdEncoding, strings.NewReader("ab c")), c.hooks())
+	io.ReadAll(r)
+	r.Read(make([]byte, 10))
+	if len(c.errs) != 1 || !errors.Is(c.errs[0], ErrCorruptInput) {
+		t.Errorf("Expected 1 ErrCorruptInput, got %v", c.errs)
+	}
+}
+
+func TestInstrumentWrongType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic, got none")
+		}
+	}()
+	InstrumentDecoder(strings.NewReader(""), StreamHooks{})
+}
@@ -0,0 +1,72 @@
+package base91
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// An ID is a unique identifier that sorts by the time it was made, in the
+// manner of a ULID: 6 bytes holding the number of milliseconds since the Unix
+// epoch, big-endian, followed by 10 random bytes. Its string form is its
+// encoding using StdOrderedEncoding, so IDs compare as strings in the same
+// order as their times, and those made in the same millisecond in an arbitrary
+// order. Every ID takes 21 characters, against 26 for a ULID and 27 for a
+// KSUID.
+type ID [16]byte
+
+// idTimeLen is the number of bytes of an ID that hold its time.
+const idTimeLen = 6
+
+// NewID returns a new ID for the current time, with random bytes read from
+// crypto/rand. It returns an error only if crypto/rand fails.
+func NewID() (ID, error) {
+	return NewIDWithTime(time.Now())
+}
+
+// NewIDWithTime is like NewID but returns an ID for the time t, which is
+// truncated to the millisecond. It panics if t is before the Unix epoch or
+// after the year 10889, the last that 6 bytes of milliseconds can hold.
+func NewIDWithTime(t time.Time) (ID, error) {
+	ms := t.UnixMilli()
+	if ms < 0 || ms >= 1<<(8*idTimeLen) {
+		panic("time out of range for base91 ID")
+	}
+
+	var id ID
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(ms))
+	copy(id[:idTimeLen], b[8-idTimeLen:])
+	if _, err := rand.Read(id[idTimeLen:]); err != nil {
+		return ID{}, err
+	}
+	return id, nil
+}
+
+// Time returns the time of id, to the millisecond.
+func (id ID) Time() time.Time {
+	var b [8]byte
+	copy(b[8-idTimeLen:], id[:idTimeLen])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(b[:])))
+}
+
+// String returns the encoding of id using StdOrderedEncoding.
+func (id ID) String() string {
+	return StdOrderedEncoding.EncodeToString(id[:])
+}
+
+// ParseID returns the ID whose string form is s. It returns a
+// CorruptInputError if s is not a valid encoding using StdOrderedEncoding, and
+// ErrWrongLength if it does not decode to 16 bytes.
+func ParseID(s string) (ID, error) {
+	var id ID
+	b, err := StdOrderedEncoding.DecodeString(s)
+	if err != nil {
+		return ID{}, err
+	}
+	if len(b) != len(id) {
+		return ID{}, ErrWrongLength
+	}
+	copy(id[:], b)
+	return id, nil
+}
@@ -0,0 +1,75 @@
+package base91
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestID(t *testing.T) {
+	now := time.Date(2024, 5, 17, 12, 30, 45, 123456789, time.UTC)
+	id, err := NewIDWithTime(now)
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if want := now.Truncate(time.Millisecond); !id.Time().Equal(want) {
+		t.Errorf("Expected time %v, got %v", want, id.Time())
+	}
+
+	s := id.String()
+	if len(s) != 21 {
+		t.Errorf("Expected 21 characters, got %d: %q", len(s), s)
+	}
+	got, err := ParseID(s)
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if got != id {
+		t.Errorf("Expected %v, got %v", id, got)
+	}
+}
+
+func TestIDOrder(t *testing.T) {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i := 0; i < 100; i++ {
+		id, err := NewIDWithTime(start.Add(time.Duration(i*i) * time.Millisecond))
+		if err != nil {
+			t.Fatalf("Got error: %v", err)
+		}
+		ids = append(ids, id.String())
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("Expected IDs in order of time, got %q", ids)
+	}
+}
+
+func TestNewIDTimeRange(t *testing.T) {
+	for _, tm := range []time.Time{time.UnixMilli(-1), time.UnixMilli(1 << 48)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for %v, got none", tm)
+				}
+			}()
+			NewIDWithTime(tm)
+		}()
+	}
+}
+
+func TestParseIDInvalid(t *testing.T) {
+	id, err := NewID()
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	s := id.String()
+
+	if _, err := ParseID(StdOrderedEncoding.EncodeToString(id[:15])); err != ErrWrongLength {
+		t.Errorf("Expected ErrWrongLength, got %v", err)
+	}
+	var e CorruptInputError
+	if _, err := ParseID(s[:20] + "\n"); !errors.As(err, &e) {
+		t.Errorf("Expected CorruptInputError, got %v", err)
+	}
+}
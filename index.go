@@ -0,0 +1,181 @@
+package base91
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+)
+
+// A FrameIndex records where each frame of a stream in the framed container
+// format (see frame.go) starts, both in the stream and in the data it decodes
+// to, so that a FrameSeeker can decode any range of the data without decoding
+// everything before it.
+type FrameIndex struct {
+	checksum bool
+	// lines[i] is the offset in the stream of the line holding frame i, and
+	// lines[len(lines)-1] is the offset of the end of the last frame.
+	lines []int64
+	// starts[i] is the offset in the decoded data of the start of frame i, and
+	// starts[len(starts)-1] is the length of the decoded data.
+	starts []int64
+}
+
+// IndexFrames reads a stream in the framed container format from r and returns
+// an index of its frames. Since every frame line begins with the length of its
+// data, the frames do not need to be decoded, so indexing is much faster than
+// decoding; damage within a frame is only detected when that frame is read.
+func IndexFrames(r io.Reader) (*FrameIndex, error) {
+	br := bufio.NewReader(r)
+	checksum, n, err := readFrameHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &FrameIndex{
+		checksum: checksum,
+		lines:    []int64{int64(n)},
+		starts:   []int64{0},
+	}
+	for {
+		line, n, err := readFrameLine(br)
+		if err == io.EOF {
+			return idx, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		length, _, err := parseFrameLength(line)
+		if err != nil {
+			return nil, err
+		}
+		idx.lines = append(idx.lines, idx.lines[len(idx.lines)-1]+int64(n))
+		idx.starts = append(idx.starts, idx.starts[len(idx.starts)-1]+int64(length))
+	}
+}
+
+// Frames returns the number of frames in the indexed stream.
+func (idx *FrameIndex) Frames() int {
+	return len(idx.starts) - 1
+}
+
+// Size returns the length of the data that the indexed stream decodes to.
+func (idx *FrameIndex) Size() int64 {
+	return idx.starts[len(idx.starts)-1]
+}
+
+// A FrameSeeker decodes an indexed stream in the framed container format,
+// giving random access to the decoded data. It implements io.ReadSeeker and
+// io.ReaderAt; only the frames that overlap a read are read and decoded.
+type FrameSeeker struct {
+	enc *Encoding
+	r   io.ReaderAt
+	idx *FrameIndex
+	off int64
+
+	// The most recently decoded frame, kept for sequential reads with Read.
+	frame     int
+	frameData []byte
+}
+
+// NewFrameSeeker returns a FrameSeeker that decodes the stream in r, which was
+// encoded with enc and indexed as idx.
+func NewFrameSeeker(enc *Encoding, r io.ReaderAt, idx *FrameIndex) *FrameSeeker {
+	return &FrameSeeker{enc: enc, r: r, idx: idx, frame: -1}
+}
+
+// Size returns the length of the decoded data.
+func (s *FrameSeeker) Size() int64 {
+	return s.idx.Size()
+}
+
+// Read reads decoded data from the current offset and advances it.
+func (s *FrameSeeker) Read(p []byte) (int, error) {
+	if s.off >= s.Size() {
+		return 0, io.EOF
+	}
+
+	i := s.findFrame(s.off)
+	if i != s.frame {
+		data, err := s.readFrame(i)
+		if err != nil {
+			return 0, err
+		}
+		s.frame, s.frameData = i, data
+	}
+	n := copy(p, s.frameData[s.off-s.idx.starts[i]:])
+	s.off += int64(n)
+	return n, nil
+}
+
+// ReadAt reads len(p) bytes of decoded data starting at offset off. It does not
+// change the offset used by Read, and it is safe to call concurrently with
+// other calls to ReadAt.
+func (s *FrameSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	n := 0
+	for n < len(p) {
+		if off >= s.Size() {
+			return n, io.EOF
+		}
+		i := s.findFrame(off)
+		data, err := s.readFrame(i)
+		if err != nil {
+			return n, err
+		}
+		k := copy(p[n:], data[off-s.idx.starts[i]:])
+		n += k
+		off += int64(k)
+	}
+	return n, nil
+}
+
+// Seek sets the offset in the decoded data for the next Read, in the manner of
+// io.Seeker.
+func (s *FrameSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.off
+	case io.SeekEnd:
+		offset += s.Size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	s.off = offset
+	return offset, nil
+}
+
+// findFrame returns the frame holding the decoded byte at off, which must be
+// less than the size of the decoded data.
+func (s *FrameSeeker) findFrame(off int64) int {
+	starts := s.idx.starts
+	return sort.Search(len(starts)-1, func(i int) bool { return starts[i+1] > off })
+}
+
+// readFrame reads and decodes frame i.
+func (s *FrameSeeker) readFrame(i int) ([]byte, error) {
+	line := make([]byte, s.idx.lines[i+1]-s.idx.lines[i])
+	// ReadAt may return io.EOF along with the whole line if it ends the stream.
+	if n, err := s.r.ReadAt(line, s.idx.lines[i]); n < len(line) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	data, err := decodeFrame(s.enc, trimFrameLine(line), s.idx.checksum)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != s.idx.starts[i+1]-s.idx.starts[i] {
+		// The stream has changed since it was indexed.
+		return nil, ErrBadFrame
+	}
+	return data, nil
+}
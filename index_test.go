@@ -0,0 +1,161 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// framed returns src in the framed container format.
+func framed(src []byte, frameSize int, checksum bool) []byte {
+	var buf bytes.Buffer
+	fw := NewFrameWriter(StdEncoding, &buf, frameSize, checksum)
+	fw.Write(src)
+	fw.Close()
+	return buf.Bytes()
+}
+
+func TestIndexFrames(t *testing.T) {
+	cases := []struct {
+		n         int
+		frameSize int
+		frames    int
+	}{
+		{0, 10, 0},
+		{1, 10, 1},
+		{10, 10, 1},
+		{11, 10, 2},
+		{1000, 64, 16},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			idx, err := IndexFrames(bytes.NewReader(framed(benchmarkData(tc.n), tc.frameSize, true)))
+			if err != nil {
+				t.Fatalf("Got indexing error: %v", err)
+			}
+			if got := idx.Frames(); got != tc.frames {
+				t.Errorf("Expected %d frames, got %d", tc.frames, got)
+			}
+			if got := idx.Size(); got != int64(tc.n) {
+				t.Errorf("Expected size %d, got %d", tc.n, got)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "base91-frames/2\n", "base91-frames/1\nx dr.J\n", "base91-frames/1\n3 dr.J"} {
+		if _, err := IndexFrames(strings.NewReader(in)); err == nil {
+			t.Errorf("Expected error for %q, got nil", in)
+		}
+	}
+}
+
+func TestFrameSeekerReadAt(t *testing.T) {
+	data := benchmarkData(1000)
+	stream := framed(data, 64, true)
+	idx, err := IndexFrames(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("Got indexing error: %v", err)
+	}
+	s := NewFrameSeeker(StdEncoding, bytes.NewReader(stream), idx)
+
+	cases := []struct {
+		off int64
+		n   int
+		err error
+	}{
+		{0, 0, nil},
+		{0, 10, nil},
+		{60, 10, nil},
+		{64, 64, nil},
+		{100, 500, nil},
+		{0, 1000, nil},
+		{990, 20, io.EOF},
+		{1000, 1, io.EOF},
+		{2000, 1, io.EOF},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			p := make([]byte, tc.n)
+			n, err := s.ReadAt(p, tc.off)
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			want := []byte{}
+			if tc.off < int64(len(data)) {
+				want = data[tc.off:]
+				if len(want) > tc.n {
+					want = want[:tc.n]
+				}
+			}
+			if !bytes.Equal(p[:n], want) {
+				t.Errorf("Expected %x, got %x", want, p[:n])
+			}
+		})
+	}
+}
+
+func TestFrameSeekerSeek(t *testing.T) {
+	data := benchmarkData(1000)
+	stream := framed(data, 64, false)
+	idx, err := IndexFrames(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("Got indexing error: %v", err)
+	}
+	s := NewFrameSeeker(StdEncoding, bytes.NewReader(stream), idx)
+
+	if _, err := s.Seek(-100, io.SeekEnd); err != nil {
+		t.Fatalf("Got seeking error: %v", err)
+	}
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("Got reading error: %v", err)
+	}
+	if !bytes.Equal(got, data[900:]) {
+		t.Errorf("Expected %x, got %x", data[900:], got)
+	}
+
+	if off, _ := s.Seek(-950, io.SeekCurrent); off != 50 {
+		t.Errorf("Expected offset 50, got %d", off)
+	}
+	p := make([]byte, 20)
+	if _, err := io.ReadFull(s, p); err != nil {
+		t.Fatalf("Got reading error: %v", err)
+	}
+	if !bytes.Equal(p, data[50:70]) {
+		t.Errorf("Expected %x, got %x", data[50:70], p)
+	}
+
+	if _, err := s.Seek(-1, io.SeekStart); err == nil {
+		t.Error("Expected error for negative offset, got nil")
+	}
+}
+
+func TestFrameSeekerDamaged(t *testing.T) {
+	data := benchmarkData(100)
+	stream := framed(data, 10, true)
+	idx, err := IndexFrames(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("Got indexing error: %v", err)
+	}
+
+	// Damage the data in the third frame.
+	lines := bytes.Split(stream, newline)
+	lines[3][len(lines[3])-1] ^= 1
+	stream = bytes.Join(lines, newline)
+	s := NewFrameSeeker(StdEncoding, bytes.NewReader(stream), idx)
+
+	p := make([]byte, 10)
+	if _, err := s.ReadAt(p, 10); err != nil {
+		t.Errorf("Got reading error: %v", err)
+	}
+	if _, err := s.ReadAt(p, 20); err == nil {
+		t.Error("Expected error reading damaged frame, got nil")
+	}
+	if _, err := s.ReadAt(p, 30); err != nil {
+		t.Errorf("Got reading error: %v", err)
+	}
+}
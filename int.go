@@ -0,0 +1,103 @@
+package base91
+
+import "encoding/binary"
+
+// The functions in this file encode unsigned integers, such as sequence numbers
+// and snowflake IDs, as short base91 strings, never wrapped, in one of two
+// forms: the 8 bytes of the integer in big-endian order, which take at most 10
+// characters whatever the value, or the varint form of encoding/binary, which
+// takes 2 characters for values below 128 and at most 13 for the largest.
+//
+// Each integer has exactly one valid encoding in each form, so decoding rejects
+// anything other than what the encoding functions produce.
+
+// EncodeUint64 returns the base91 encoding of the 8 big-endian bytes of v.
+func (enc *Encoding) EncodeUint64(v uint64) string {
+	return string(enc.AppendUint64(nil, v))
+}
+
+// AppendUint64 appends the encoding of v produced by EncodeUint64 to dst and
+// returns the extended slice.
+func (enc *Encoding) AppendUint64(dst []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return enc.appendUnwrapped(dst, b[:])
+}
+
+// DecodeUint64 returns the integer encoded in s by EncodeUint64. It returns
+// ErrWrongLength if s does not decode to 8 bytes, and a CorruptInputError if
+// s is not exactly the encoding of an integer.
+func (enc *Encoding) DecodeUint64(s string) (uint64, error) {
+	var b [8]byte
+	if _, err := enc.decodeCanonical(b[:], stringBytes(s), true); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// EncodeUvarint returns the base91 encoding of the varint form of v.
+func (enc *Encoding) EncodeUvarint(v uint64) string {
+	return string(enc.AppendUvarint(nil, v))
+}
+
+// AppendUvarint appends the encoding of v produced by EncodeUvarint to dst and
+// returns the extended slice.
+func (enc *Encoding) AppendUvarint(dst []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return enc.appendUnwrapped(dst, b[:n])
+}
+
+// DecodeUvarint returns the integer encoded in s by EncodeUvarint. It returns
+// ErrWrongLength if s does not decode to a single complete varint, and a
+// CorruptInputError if s is not exactly the encoding of an integer.
+func (enc *Encoding) DecodeUvarint(s string) (uint64, error) {
+	var b [binary.MaxVarintLen64]byte
+	n, err := enc.decodeCanonical(b[:], stringBytes(s), false)
+	if err != nil {
+		return 0, err
+	}
+	v, k := binary.Uvarint(b[:n])
+	if k != n || n == 0 || n > 1 && b[n-1] == 0 {
+		// The varint is incomplete, overflows, is followed by more data, or
+		// has superfluous zero bytes, which PutUvarint never writes.
+		return 0, ErrWrongLength
+	}
+	return v, nil
+}
+
+// appendUnwrapped appends the unwrapped encoding of src to dst.
+func (enc *Encoding) appendUnwrapped(dst, src []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, maxEncodedLen(len(src)))...)
+	k, _ := enc.encode91(dst[n:], src)
+	return dst[:n+k]
+}
+
+// decodeCanonical decodes src, which must be exactly the unwrapped encoding
+// that encode91 produces, into dst, which must be at most 64 bytes long. It
+// returns ErrWrongLength if the data does not fit in dst or, if exact is true,
+// does not fill it.
+func (enc *Encoding) decodeCanonical(dst, src []byte, exact bool) (int, error) {
+	n, err := enc.decodeUnwrapped(dst, src)
+	if err == ErrShortDst || err == nil && exact && n != len(dst) {
+		return 0, ErrWrongLength
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Decoding skips line breaks and may accept other variations, so check
+	// that encoding the data again gives src.
+	var buf [79]byte
+	k, _ := enc.encode91(buf[:], dst[:n])
+	for i := range src {
+		if i >= k || src[i] != buf[i] {
+			return 0, enc.corruptInputError(src, i)
+		}
+	}
+	if len(src) < k {
+		return 0, ErrWrongLength
+	}
+	return n, nil
+}
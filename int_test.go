@@ -0,0 +1,72 @@
+package base91
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestUint64(t *testing.T) {
+	values := []uint64{0, 1, 127, 128, 1 << 32, 1<<63 + 12345, math.MaxUint64}
+	for i, v := range values {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			s := StdEncoding.EncodeUint64(v)
+			if len(s) > 10 {
+				t.Errorf("Expected at most 10 characters, got %q", s)
+			}
+			if got := string(StdEncoding.AppendUint64([]byte("x"), v)); got != "x"+s {
+				t.Errorf("Expected %q, got %q", "x"+s, got)
+			}
+			got, err := StdEncoding.DecodeUint64(s)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if got != v {
+				t.Errorf("Expected %d, got %d", v, got)
+			}
+
+			s = StdEncoding.EncodeUvarint(v)
+			if len(s) > 13 || v < 128 && len(s) != 2 {
+				t.Errorf("Unexpected length of %q", s)
+			}
+			if got := string(StdEncoding.AppendUvarint([]byte("x"), v)); got != "x"+s {
+				t.Errorf("Expected %q, got %q", "x"+s, got)
+			}
+			got, err = StdEncoding.DecodeUvarint(s)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if got != v {
+				t.Errorf("Expected %d, got %d", v, got)
+			}
+		})
+	}
+}
+
+func TestUint64Invalid(t *testing.T) {
+	s := StdEncoding.EncodeUint64(1 << 40)
+	cases := []struct {
+		decode func(string) (uint64, error)
+		s      string
+		err    error
+	}{
+		{StdEncoding.DecodeUint64, "", ErrWrongLength},
+		{StdEncoding.DecodeUint64, s[:len(s)-2], ErrWrongLength},
+		{StdEncoding.DecodeUint64, s + "AA", ErrWrongLength},
+		{StdEncoding.DecodeUint64, s[:4] + "\n" + s[4:], ErrCorruptInput},
+		{StdEncoding.DecodeUvarint, "", ErrWrongLength},
+		{StdEncoding.DecodeUvarint, StdEncoding.EncodeToString([]byte{0x80}), ErrWrongLength},
+		{StdEncoding.DecodeUvarint, StdEncoding.EncodeToString([]byte{0x80, 0}), ErrWrongLength},
+		{StdEncoding.DecodeUvarint, StdEncoding.EncodeToString([]byte{1, 2}), ErrWrongLength},
+		{StdEncoding.DecodeUvarint, "AB\n", ErrCorruptInput},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if _, err := tc.decode(tc.s); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
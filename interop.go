@@ -0,0 +1,72 @@
+package base91
+
+import "strconv"
+
+// A Vector is a test vector: data and its base91 encoding.
+type Vector struct {
+	Name    string `json:"name"`
+	Data    []byte `json:"data"` // Encoded in JSON as standard base64.
+	Encoded string `json:"encoded"`
+}
+
+// InteropVectors returns test vectors for the interop profile (see Interop)
+// with the standard alphabet, which implementations in other languages can
+// check themselves against, for example after marshaling them to JSON. Besides
+// empty input and inputs of every length up to 32 bytes, they cover every
+// value of a single byte, groups on either side of the threshold between 13
+// and 14 bits, and every kind of final partial group: each number of leftover
+// bits, encoded as one symbol or as a pair. The vectors are always the same,
+// in the same order.
+func InteropVectors() []Vector {
+	var vs []Vector
+	add := func(name string, data []byte) {
+		vs = append(vs, Vector{Name: name, Data: data, Encoded: StdEncoding.EncodeToString(data)})
+	}
+
+	add("empty", []byte{})
+	for n := 1; n <= 32; n++ {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		add("length/"+strconv.Itoa(n), data)
+	}
+	for c := 0; c < 256; c++ {
+		add("byte/"+strconv.Itoa(c), []byte{byte(c)})
+	}
+
+	// A group takes 14 bits if its low 13 bits are at most 88, and 13 otherwise.
+	for _, v := range []int{0, 1, 88, 89, 90, 8191} {
+		for _, high := range []int{0, 7} {
+			x := v | high<<13
+			add("group/"+strconv.Itoa(v)+"/"+strconv.Itoa(high), []byte{byte(x), byte(x >> 8)})
+		}
+	}
+
+	// Find the first input, in a fixed order, that ends with each possible
+	// final partial group.
+	seen := make(map[string]bool)
+	var buf [2 * 16]byte
+	for n := 1; n <= 16; n++ {
+		for c := 0; c < 256; c++ {
+			data := make([]byte, n)
+			for i := range data {
+				data[i] = byte(c)
+			}
+			e := encoder{enc: StdEncoding}
+			e.encodeBlock(buf[:], data)
+			kind := "single"
+			if e.numBits == 0 {
+				kind = "none"
+			} else if e.numBits > 7 || e.queue > 90 {
+				kind = "pair"
+			}
+			name := "tail/" + strconv.Itoa(int(e.numBits)) + "/" + kind
+			if !seen[name] {
+				seen[name] = true
+				add(name, data)
+			}
+		}
+	}
+	return vs
+}
@@ -0,0 +1,66 @@
+package base91
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInteropVectors(t *testing.T) {
+	enc := NewEncodingWithOptions(encodeStd, Interop())
+	vs := InteropVectors()
+
+	names := make(map[string]bool)
+	tails := 0
+	for _, v := range vs {
+		if names[v.Name] {
+			t.Errorf("Duplicate vector name %q", v.Name)
+		}
+		names[v.Name] = true
+		if strings.HasPrefix(v.Name, "tail/") {
+			tails++
+		}
+
+		if got := enc.EncodeToString(v.Data); got != v.Encoded {
+			t.Errorf("%s: expected %q, got %q", v.Name, v.Encoded, got)
+		}
+		decoded, err := enc.DecodeString(v.Encoded)
+		if err != nil {
+			t.Fatalf("%s: got decoding error: %v", v.Name, err)
+		}
+		if !bytes.Equal(decoded, v.Data) {
+			t.Errorf("%s: expected %x, got %x", v.Name, v.Data, decoded)
+		}
+	}
+
+	// 1 to 6 leftover bits fit in a single symbol, 8 to 13 need a pair, 7 may
+	// take either, and there may be none.
+	if tails != 15 {
+		t.Errorf("Expected 15 kinds of final group, got %d", tails)
+	}
+
+	if got := InteropVectors(); len(got) != len(vs) || got[len(got)-1].Name != vs[len(vs)-1].Name {
+		t.Errorf("Expected the same vectors on every call")
+	}
+}
+
+func TestInteropVectorsJSON(t *testing.T) {
+	b, err := json.Marshal(InteropVectors()[:2])
+	if err != nil {
+		t.Fatalf("Got marshaling error: %v", err)
+	}
+	want := `[{"name":"empty","data":"","encoded":""},{"name":"length/1","data":"AA==","encoded":"AA"}]`
+	if string(b) != want {
+		t.Errorf("Expected %s, got %s", want, b)
+	}
+}
+
+func TestInteropStrict(t *testing.T) {
+	enc := NewEncodingWithOptions(encodeStd, Interop())
+	for _, s := range []string{"dr/2s)uC\n", "dr/2 s)uC", "drz~"} {
+		if _, err := enc.DecodeString(s); err == nil {
+			t.Errorf("%q: expected error, got none", s)
+		}
+	}
+}
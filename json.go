@@ -0,0 +1,45 @@
+package base91
+
+import "encoding/json"
+
+// Bytes is a byte slice that is marshaled to JSON as a base91 string, in the
+// standard encoding, rather than as base64, as encoding/json does for []byte.
+// A struct field opts into base91 by having type Bytes instead of []byte:
+//
+//	type Message struct {
+//		Payload base91.Bytes `json:"payload"`
+//	}
+//
+// A nil Bytes is marshaled as null. Since the standard alphabet includes '"',
+// which JSON escapes, and '<', '>', and '&', which encoding/json also escapes
+// unless HTML escaping is turned off with Encoder.SetEscapeHTML, the output is
+// a little longer than the plain encoding, but still shorter than base64.
+type Bytes []byte
+
+// MarshalJSON returns the JSON string holding the base91 encoding of b, or null
+// if b is nil.
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(StdEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON sets *b to the data encoded in the JSON string data, or to nil
+// if data is null.
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*b = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
@@ -0,0 +1,56 @@
+package base91
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBytesJSON(t *testing.T) {
+	type message struct {
+		Payload Bytes `json:"payload"`
+	}
+
+	cases := []struct {
+		payload Bytes
+		json    string
+	}{
+		{nil, `{"payload":null}`},
+		{Bytes{}, `{"payload":""}`},
+		{Bytes("foobar"), `{"payload":"dr/2s)uC"}`},
+		{Bytes{0xff, 0xff}, `{"payload":"B\"H"}`},
+		{Bytes("test"), `{"payload":"fPNKd"}`},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			b, err := json.Marshal(message{tc.payload})
+			if err != nil {
+				t.Fatalf("Got marshaling error: %v", err)
+			}
+			if string(b) != tc.json {
+				t.Errorf("Expected %s, got %s", tc.json, b)
+			}
+
+			var m message
+			if err := json.Unmarshal(b, &m); err != nil {
+				t.Fatalf("Got unmarshaling error: %v", err)
+			}
+			if (m.Payload == nil) != (tc.payload == nil) || !bytes.Equal(m.Payload, tc.payload) {
+				t.Errorf("Expected %#v, got %#v", tc.payload, m.Payload)
+			}
+		})
+	}
+}
+
+func TestBytesJSONInvalid(t *testing.T) {
+	var b Bytes
+	if err := json.Unmarshal([]byte(`"ab c"`), &b); !errors.Is(err, ErrCorruptInput) {
+		t.Errorf("Expected ErrCorruptInput, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`12`), &b); err == nil {
+		t.Errorf("Expected error for a number, got none")
+	}
+}
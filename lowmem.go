@@ -0,0 +1,22 @@
+//go:build tinygo || base91_lowmem
+
+package base91
+
+// This file configures low-memory builds, which are used automatically under
+// TinyGo and may be requested elsewhere with the base91_lowmem build tag. They
+// are meant for microcontrollers and other targets where a few hundred KiB of
+// RAM matters more than throughput.
+//
+// In low-memory builds, the EncodeTable and DecodeTable options are ignored, so
+// that the tables are never allocated, and the convenience functions do not
+// keep buffers in a pool. The decode map of an Encoding is only 256 bytes and
+// is built eagerly as usual: TinyGo runs package initialization at compile
+// time where it can, so StdEncoding is built when the program is compiled
+// rather than at startup.
+
+// lowMemory reports whether this is a low-memory build.
+const lowMemory = true
+
+// maxPooledLen is the size of the largest scratch buffer that is kept in
+// scratchPool. Buffers are not pooled in low-memory builds.
+const maxPooledLen = 0
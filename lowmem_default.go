@@ -0,0 +1,11 @@
+//go:build !(tinygo || base91_lowmem)
+
+package base91
+
+// lowMemory reports whether this is a low-memory build. See lowmem.go.
+const lowMemory = false
+
+// maxPooledLen is the size of the largest scratch buffer that is kept in
+// scratchPool. Larger inputs are rare enough that they do not churn the
+// garbage collector, and pooling their buffers would pin a lot of memory.
+const maxPooledLen = 64 << 10
@@ -0,0 +1,46 @@
+package base91
+
+import "io"
+
+// mimeCols is the line length of MIME output, as for base64 in RFC 2045.
+const mimeCols = 76
+
+var crlf = []byte{'\r', '\n'}
+
+// NewMIMEEncoder returns a stream encoder, like NewEncoder, whose output is
+// suitable as the body of a MIME part or email with an experimental base91
+// Content-Transfer-Encoding: lines of at most 76 bytes, each ending with CRLF
+// ("\r\n"), as RFC 5322 requires, including the last. The wrap and trailing
+// newline settings of enc are overridden.
+//
+// Mail software may fold, unfold, and trim whitespace, so NewMIMEEncoder
+// panics if the alphabet of enc contains a space or control character, as
+// reported by CheckAlphabetSafeFor for MIMEHeader. The standard alphabet is
+// safe.
+func NewMIMEEncoder(enc *Encoding, w io.Writer) io.WriteCloser {
+	if CheckAlphabetSafeFor(enc, MIMEHeader) != "" {
+		panic("encoding alphabet is not safe for MIME")
+	}
+	e := enc.WithWrap(mimeCols)
+	e.trailingNewline = true
+	e.rebuildDecodeMap()
+	return &streamEncoder{e: encoder{enc: e, lineBreak: crlf}, w: w}
+}
+
+// NewMIMEDecoder returns a stream decoder, like NewDecoder, for MIME data
+// encoded with enc. It is tolerant of what mail software does to text: it
+// accepts lines of any length, ending with CRLF or a bare LF, and ignores
+// spaces and tabs, which mail software may add when folding lines or remove
+// at the ends of lines, if they are not in the alphabet. It does so even if
+// enc is strict.
+func NewMIMEDecoder(enc *Encoding, r io.Reader) io.Reader {
+	e := *enc
+	e.strict = false
+	for _, c := range []byte{' ', '\t'} {
+		if !e.isSymbol(c) {
+			e.ignore += string(c)
+		}
+	}
+	e.rebuildDecodeMap()
+	return NewDecoder(&e, r)
+}
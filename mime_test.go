@@ -0,0 +1,83 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMIMEEncoder(t *testing.T) {
+	data := benchmarkData(1000)
+	for i, n := range []int{0, 1, 57, 61, 62, 1000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewMIMEEncoder(StdEncoding, &buf)
+			w.Write(data[:n])
+			if err := w.Close(); err != nil {
+				t.Fatalf("Got closing error: %v", err)
+			}
+
+			want := StdEncoding.WithWrap(76).EncodeToString(data[:n])
+			if n > 0 {
+				want += "\n"
+			}
+			want = strings.ReplaceAll(want, "\n", "\r\n")
+			if got := buf.String(); got != want {
+				t.Errorf("Expected %q, got %q", want, got)
+			}
+
+			decoded, err := io.ReadAll(NewMIMEDecoder(StdEncoding, &buf))
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data[:n]) {
+				t.Errorf("Expected %x, got %x", data[:n], decoded)
+			}
+		})
+	}
+}
+
+func TestMIMEEncoderUnsafeAlphabet(t *testing.T) {
+	enc, err := NewEncodingOmitting("\"'-\\")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for alphabet with a space")
+		}
+	}()
+	NewMIMEEncoder(enc, io.Discard)
+}
+
+func TestMIMEDecoder(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	cases := []struct {
+		enc *Encoding
+		in  string
+	}{
+		{StdEncoding, "dr/2s)uC"},
+		{StdEncoding, "dr/2\r\ns)uC\r\n"},
+		{StdEncoding, "dr/2\ns)uC\n"},
+		{StdEncoding, "dr/2 \t\r\n s)uC  \r\n"},
+		{strict, "dr/2\r\n\ts)uC\r\n"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			decoded, err := io.ReadAll(NewMIMEDecoder(tc.enc, strings.NewReader(tc.in)))
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if string(decoded) != "foobar" {
+				t.Errorf("Expected %q, got %q", "foobar", decoded)
+			}
+		})
+	}
+
+	if _, err := io.ReadAll(NewMIMEDecoder(StdEncoding, strings.NewReader("dr-2"))); err == nil {
+		t.Error("Expected error for invalid input, got nil")
+	}
+}
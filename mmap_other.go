@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package base91
+
+import "os"
+
+// mapFile calls f with the contents of the named file. Memory mapping is not
+// supported on this platform, so the file is read into memory.
+func mapFile(path string, f func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return f(data)
+}
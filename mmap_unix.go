@@ -0,0 +1,39 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package base91
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mapFile calls f with the contents of the named file, which are
+// memory-mapped for the duration of the call. f must not retain the slice.
+func mapFile(path string, f func([]byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	if size == 0 {
+		// An empty mapping is an error, and there is nothing to map anyway.
+		return f(nil)
+	}
+	if int64(int(size)) != size {
+		return &os.PathError{Op: "mmap", Path: path, Err: errors.New("file too large")}
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return &os.PathError{Op: "mmap", Path: path, Err: err}
+	}
+	defer syscall.Munmap(data)
+	return f(data)
+}
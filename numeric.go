@@ -0,0 +1,107 @@
+package base91
+
+// A NumericEncoding encodes data as a single number written in base 91, in the
+// manner of base58: the data, apart from any leading zero bytes, is read as a
+// big-endian integer and written with the most significant digit first, and
+// each leading zero byte is written as the first symbol of the alphabet. Unlike
+// base91, whose output reflects how it splits data into groups of bits, the
+// output has no internal structure, which some fingerprint and ID formats
+// require. Encoding and decoding take time quadratic in the length of the data,
+// so a NumericEncoding is meant for short values, such as keys and hashes.
+type NumericEncoding struct {
+	enc *Encoding
+}
+
+// Numeric returns a NumericEncoding that uses the alphabet of enc. Options of
+// enc that concern wrapping, line breaks, and invalid input do not apply: the
+// output is never wrapped, and decoding accepts only symbols of the alphabet.
+func (enc *Encoding) Numeric() *NumericEncoding {
+	return &NumericEncoding{enc: enc}
+}
+
+// String returns a short description of enc for use in messages, such as
+// "base91/std,numeric".
+func (enc *NumericEncoding) String() string {
+	return "base91/" + enc.enc.alphabetID() + ",numeric"
+}
+
+// EncodedLen returns an upper bound on the length in bytes of the encoding of
+// n bytes of data.
+func (enc *NumericEncoding) EncodedLen(n int) int {
+	// Each byte is worth log(256)/log(91) < 1.23 digits.
+	return n*123/100 + 1
+}
+
+// EncodeToString returns the encoding of src using enc.
+func (enc *NumericEncoding) EncodeToString(src []byte) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+
+	// The digits of the number, least significant first.
+	digits := make([]byte, 0, enc.EncodedLen(len(src)-zeros))
+	for _, c := range src[zeros:] {
+		carry := uint32(c)
+		for i, d := range digits {
+			carry += uint32(d) << 8
+			digits[i] = byte(carry % 91)
+			carry /= 91
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%91))
+			carry /= 91
+		}
+	}
+
+	buf := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		buf[i] = enc.enc.encode[0]
+	}
+	for i, d := range digits {
+		buf[len(buf)-1-i] = enc.enc.encode[d]
+	}
+	return string(buf)
+}
+
+// DecodedLen returns an upper bound on the length in bytes of the data that n
+// bytes of encoded data decode to.
+func (enc *NumericEncoding) DecodedLen(n int) int {
+	return n
+}
+
+// DecodeString returns the bytes represented by the string s, which was
+// encoded with enc. If s contains a byte outside the alphabet, DecodeString
+// returns a CorruptInputError.
+func (enc *NumericEncoding) DecodeString(s string) ([]byte, error) {
+	src := stringBytes(s)
+	zero := enc.enc.encode[0]
+	zeros := 0
+	for zeros < len(src) && src[zeros] == zero {
+		zeros++
+	}
+
+	// The bytes of the number, least significant first.
+	data := make([]byte, 0, enc.DecodedLen(len(src)-zeros))
+	for i, c := range src[zeros:] {
+		if !enc.enc.isSymbol(c) {
+			return nil, newCorruptInputError(src, zeros+i, enc.String())
+		}
+		carry := uint32(enc.enc.decodeMap[c])
+		for j, b := range data {
+			carry += uint32(b) * 91
+			data[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			data = append(data, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	dst := make([]byte, zeros+len(data))
+	for i, b := range data {
+		dst[len(dst)-1-i] = b
+	}
+	return dst, nil
+}
@@ -0,0 +1,82 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestNumericEncoding(t *testing.T) {
+	enc := StdEncoding.Numeric()
+	cases := []struct {
+		data    []byte
+		encoded string
+	}{
+		{[]byte{}, ""},
+		{[]byte{0}, "A"},
+		{[]byte{0, 0}, "AA"},
+		{[]byte{1}, "B"},
+		{[]byte{90}, "\""},
+		{[]byte{91}, "BA"},
+		{[]byte{0, 91}, "ABA"},
+		{[]byte{1, 0}, "C:"}, // 256 = 2*91 + 74
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := enc.EncodeToString(tc.data); got != tc.encoded {
+				t.Errorf("Expected %q, got %q", tc.encoded, got)
+			}
+			decoded, err := enc.DecodeString(tc.encoded)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, tc.data) {
+				t.Errorf("Expected %x, got %x", tc.data, decoded)
+			}
+		})
+	}
+}
+
+func TestNumericEncodingBigInt(t *testing.T) {
+	enc := StdEncoding.Numeric()
+	for n := 1; n < 200; n += 7 {
+		data := benchmarkData(n)
+		data[0] |= 1
+		s := enc.EncodeToString(data)
+		if len(s) > enc.EncodedLen(n) {
+			t.Errorf("n=%d: encoded to %d bytes, more than EncodedLen %d", n, len(s), enc.EncodedLen(n))
+		}
+
+		// The digits of s, read as a number in base 91, give the data.
+		v := new(big.Int)
+		for i := 0; i < len(s); i++ {
+			v.Mul(v, big.NewInt(91))
+			v.Add(v, big.NewInt(int64(StdEncoding.decodeMap[s[i]])))
+		}
+		if !bytes.Equal(v.Bytes(), data) {
+			t.Fatalf("n=%d: %q is %x, expected %x", n, s, v.Bytes(), data)
+		}
+
+		decoded, err := enc.DecodeString(s)
+		if err != nil {
+			t.Fatalf("n=%d: got decoding error: %v", n, err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("n=%d: expected %x, got %x", n, data, decoded)
+		}
+	}
+}
+
+func TestNumericEncodingInvalid(t *testing.T) {
+	_, err := StdEncoding.Numeric().DecodeString("AAB\nC")
+	var e CorruptInputError
+	if !errors.As(err, &e) {
+		t.Fatalf("Expected CorruptInputError, got %v", err)
+	}
+	if e.Offset != 3 || e.Encoding != "base91/std,numeric" {
+		t.Errorf("Expected offset 3 in base91/std,numeric, got %d in %s", e.Offset, e.Encoding)
+	}
+}
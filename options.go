@@ -0,0 +1,184 @@
+package base91
+
+// An Option configures an Encoding created by NewEncodingWithOptions.
+type Option func(*Encoding)
+
+// NewEncodingWithOptions returns a new Encoding defined by the given alphabet,
+// which must satisfy the same requirements as for NewEncoding, and configured
+// by opts. Options are applied in order, so a later option may override an
+// earlier one.
+func NewEncodingWithOptions(alphabet string, opts ...Option) *Encoding {
+	e := NewEncoding(alphabet)
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.buildDecodeMap()
+	return e
+}
+
+// Wrap returns an Option that breaks encoded output into lines of at most cols
+// bytes, as described for WithWrap.
+func Wrap(cols int) Option {
+	if cols < 0 {
+		panic("wrap column count is negative")
+	}
+	return func(e *Encoding) {
+		e.wrap = cols
+		e.sep = '\n'
+	}
+}
+
+// Group returns an Option that breaks encoded output into groups of size bytes
+// separated by sep, as in "xxxxx-xxxxx-xxxxx" for a license key, which is
+// easier for people to read and type than one long run of symbols. It works
+// like Wrap with sep in place of the line break, and overrides it; the last
+// group may be shorter. Decoding skips sep, even when the Encoding is strict.
+// sep must not be in the encoding alphabet.
+func Group(size int, sep byte) Option {
+	if size <= 0 {
+		panic("group size is not positive")
+	}
+	return func(e *Encoding) {
+		if e.isSymbol(sep) {
+			panic("group separator is in the encoding alphabet")
+		}
+		e.wrap = size
+		e.sep = sep
+	}
+}
+
+// TrailingNewline returns an Option that ends non-empty encoded output with
+// '\n'. Combined with Wrap, this makes every line of output, including the last,
+// end with a line break.
+func TrailingNewline() Option {
+	return func(e *Encoding) {
+		e.trailingNewline = true
+	}
+}
+
+// IgnoreChars returns an Option that makes decoding skip the bytes in chars,
+// in the same way that it skips '\r' and '\n'. chars must not contain any byte
+// in the encoding alphabet.
+func IgnoreChars(chars string) Option {
+	return func(e *Encoding) {
+		for i := 0; i < len(chars); i++ {
+			if e.isSymbol(chars[i]) {
+				panic("ignored character is in the encoding alphabet")
+			}
+		}
+		e.ignore += chars
+	}
+}
+
+// Strict returns an Option that makes decoding reject any byte that is not in
+// the encoding alphabet, other than the '\n' line breaks and group separators
+// that the Encoding itself emits when it wraps or groups output or adds a
+// trailing newline, and those only where it emits them: every line or group
+// but the last must be full, none may be empty, and the trailing newline must
+// be present if and only if the Encoding adds one. In particular '\r' and bytes
+// passed to IgnoreChars are rejected. Strict decoding also rejects a final
+// symbol group that the encoder would not have produced, such as one with
+// non-zero padding bits, which would otherwise be silently discarded. Strict
+// overrides SkipInvalid and ReplaceInvalid.
+func Strict() Option {
+	return func(e *Encoding) {
+		e.strict = true
+	}
+}
+
+// SkipInvalid returns an Option that makes decoding skip bytes that are
+// neither in the encoding alphabet nor otherwise ignored, instead of returning
+// CorruptInputError, in the manner of GNU base64 --ignore-garbage. It is meant
+// for recovering data from damaged or noisy input such as logs.
+func SkipInvalid() Option {
+	return func(e *Encoding) {
+		e.invalid = 0xfe
+	}
+}
+
+// ReplaceInvalid returns an Option that makes decoding treat bytes that are
+// neither in the encoding alphabet nor otherwise ignored as if they were the
+// symbol c, instead of returning CorruptInputError. Unlike SkipInvalid, this
+// keeps the symbols that follow an invalid byte aligned with their positions.
+// c must be in the encoding alphabet.
+func ReplaceInvalid(c byte) Option {
+	return func(e *Encoding) {
+		if !e.isSymbol(c) {
+			panic("replacement character is not in the encoding alphabet")
+		}
+		e.invalid = e.decodeMap[c]
+	}
+}
+
+// MaxDecodedLen returns an Option that limits the length of the data that
+// DecodeString and other functions that allocate the result will decode to n
+// bytes. Longer input is rejected with ErrTooLarge before the result buffer is
+// allocated, so that untrusted input cannot cause huge allocations. A limit of
+// 0 means no limit. Decode is not affected, since its caller supplies dst.
+func MaxDecodedLen(n int) Option {
+	if n < 0 {
+		panic("maximum decoded length is negative")
+	}
+	return func(e *Encoding) {
+		e.maxDecodedLen = n
+	}
+}
+
+// EncodeTable returns an Option that precomputes a 16 KiB table mapping every
+// value the encoder can emit as a symbol pair to its two symbols, so that
+// encoding needs a single table load per pair rather than a division and two
+// alphabet lookups. It speeds up bulk encoding at the cost of memory, so it is
+// off by default. In low-memory builds (see lowmem.go) it has no effect.
+func EncodeTable() Option {
+	return func(e *Encoding) {
+		if !lowMemory {
+			e.buildEncodePairs()
+		}
+	}
+}
+
+// DecodeTable returns an Option that precomputes a 128 KiB table mapping every
+// pair of input bytes to the value they encode, so that decoding needs a single
+// table load per pair of symbols rather than two decode map lookups, a multiply,
+// and an add. Like EncodeTable, it trades memory for speed, is off by default,
+// and has no effect in low-memory builds.
+func DecodeTable() Option {
+	return func(e *Encoding) {
+		e.decodeTable = !lowMemory
+	}
+}
+
+// b91encCols is the default line length of the reference b91enc tool.
+const b91encCols = 76
+
+// B91Enc returns an Option that formats encoded output exactly as the reference
+// b91enc command-line tool does by default: in lines of 76 bytes, each ending
+// with '\n', including the last. Empty input still encodes to empty output.
+// With the standard alphabet, output can then be compared byte for byte with
+// files produced by the original implementation.
+func B91Enc() Option {
+	return func(e *Encoding) {
+		e.wrap = b91encCols
+		e.sep = '\n'
+		e.trailingNewline = true
+	}
+}
+
+// Interop returns an Option that configures an Encoding for the interop
+// profile: the behavior on which base91 implementations in other languages can
+// be expected to agree, and which data exchanged with them should follow.
+// Under it, empty input encodes to empty output and back; the final partial
+// group is encoded as the reference implementation does, as a single symbol
+// if it has at most 7 bits with a value below 91 and as a pair otherwise;
+// output has no line breaks; and decoding rejects any byte outside the
+// alphabet, including line breaks, and any final group that the encoder would
+// not have produced. It overrides Wrap, TrailingNewline, SkipInvalid, and
+// ReplaceInvalid given before it. InteropVectors returns test vectors for the
+// profile.
+func Interop() Option {
+	return func(e *Encoding) {
+		e.wrap = 0
+		e.trailingNewline = false
+		e.strict = true
+	}
+}
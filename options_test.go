@@ -0,0 +1,554 @@
+package base91
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewEncodingWithOptions(t *testing.T) {
+	cases := []struct {
+		opts    []Option
+		encoded string
+		name    string
+	}{
+		{nil, "dr/2s)uC", "base91/std"},
+		{[]Option{Wrap(3)}, "dr/\n2s)\nuC", "base91/std,wrap=3"},
+		{[]Option{TrailingNewline()}, "dr/2s)uC\n", "base91/std,nl"},
+		{[]Option{Wrap(4), TrailingNewline()}, "dr/2\ns)uC\n", "base91/std,wrap=4,nl"},
+		{[]Option{Wrap(3), Wrap(0)}, "dr/2s)uC", "base91/std"},
+		{[]Option{Strict(), Wrap(3)}, "dr/\n2s)\nuC", "base91/std,wrap=3,strict"},
+		{[]Option{B91Enc()}, "dr/2s)uC\n", "base91/std,wrap=76,nl"},
+		{[]Option{B91Enc(), Interop()}, "dr/2s)uC", "base91/std,strict"},
+		{[]Option{Group(3, '-')}, "dr/-2s)-uC", "base91/std,group=3,sep='-'"},
+		{[]Option{Group(4, ' '), TrailingNewline(), Strict()}, "dr/2 s)uC\n", "base91/std,group=4,sep=' ',nl,strict"},
+		{[]Option{Group(3, '-'), Wrap(4)}, "dr/2\ns)uC", "base91/std,wrap=4"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			enc := NewEncodingWithOptions(encodeStd, tc.opts...)
+
+			if got := enc.String(); got != tc.name {
+				t.Errorf("Expected name %q, got %q", tc.name, got)
+			}
+
+			src := []byte("foobar")
+			dst := make([]byte, enc.EncodedLen(len(src)))
+			n, err := enc.Encode(dst, src)
+			if err != nil {
+				t.Fatalf("Got encoding error: %v", err)
+			}
+			if got := string(dst[:n]); got != tc.encoded {
+				t.Errorf("Expected %q, got %q", tc.encoded, got)
+			}
+
+			decoded, err := enc.DecodeString(tc.encoded)
+			if err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if string(decoded) != "foobar" {
+				t.Errorf("Expected %q, got %q", "foobar", decoded)
+			}
+		})
+	}
+}
+
+func TestB91Enc(t *testing.T) {
+	enc := NewEncodingWithOptions(encodeStd, B91Enc())
+	for _, n := range []int{0, 1, 61, 62, 100, 1000} {
+		data := benchmarkData(n)
+		got := enc.EncodeToString(data)
+		if n == 0 {
+			if got != "" {
+				t.Errorf("Expected empty output, got %q", got)
+			}
+			continue
+		}
+
+		// Every line ends with a line break, and all but the last hold 76
+		// bytes.
+		want := StdEncoding.EncodeToString(data)
+		lines := strings.SplitAfter(got, "\n")
+		if last := lines[len(lines)-1]; last != "" {
+			t.Fatalf("n=%d: expected output ending with a line break, got %q", n, last)
+		}
+		lines = lines[:len(lines)-1]
+		for i, line := range lines {
+			if len(line) != 77 && i < len(lines)-1 || len(line) > 77 {
+				t.Errorf("n=%d: line %d has %d bytes", n, i, len(line))
+			}
+		}
+		if joined := strings.Replace(got, "\n", "", -1); joined != want {
+			t.Errorf("n=%d: expected %q, got %q", n, want, joined)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	enc := NewEncodingWithOptions(encodeStd, Group(5, '-'), Strict())
+	data := benchmarkData(100)
+	s := enc.EncodeToString(data)
+	for i, group := range strings.Split(s, "-") {
+		if len(group) != 5 && i < strings.Count(s, "-") {
+			t.Errorf("Group %d has %d bytes", i, len(group))
+		}
+	}
+	if !enc.IsCanonical([]byte(s)) {
+		t.Errorf("Expected %q to be canonical", s)
+	}
+
+	// The stream encoder groups output the same way.
+	var buf bytes.Buffer
+	w := NewEncoder(enc, &buf)
+	w.Write(data[:33])
+	w.Write(data[33:])
+	w.Close()
+	if buf.String() != s {
+		t.Errorf("Expected %q, got %q", s, buf.String())
+	}
+
+	decoded, err := enc.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Expected %x, got %x", data, decoded)
+	}
+
+	// Strict decoding accepts only the separator, but the default decoding
+	// also skips line breaks.
+	if _, err := enc.DecodeString(strings.Replace(s, "-", "\n", 1)); err == nil {
+		t.Errorf("Expected error for a line break, got none")
+	}
+	loose := NewEncodingWithOptions(encodeStd, Group(5, '-'))
+	if _, err := loose.DecodeString(strings.Replace(s, "-", "\n", 1)); err != nil {
+		t.Errorf("Got decoding error: %v", err)
+	}
+}
+
+func TestGroupSeparatorInAlphabet(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic, got none")
+		}
+	}()
+	NewEncodingWithOptions(encodeStd, Group(5, 'A'))
+}
+
+func TestIgnoreChars(t *testing.T) {
+	enc := NewEncodingWithOptions(encodeStd, IgnoreChars(" \t"))
+
+	got, err := enc.DecodeString("dr/2 s)\tuC\r\n")
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if string(got) != "foobar" {
+		t.Errorf("Expected %q, got %q", "foobar", got)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	cases := []struct {
+		enc   *Encoding
+		input string
+		ok    bool
+	}{
+		{NewEncodingWithOptions(encodeStd, Strict()), "dr/2s)uC", true},
+		{NewEncodingWithOptions(encodeStd, Strict()), "dr/2s)uC\n", false},
+		{NewEncodingWithOptions(encodeStd, Strict(), TrailingNewline()), "dr/2s)uC\n", true},
+		{NewEncodingWithOptions(encodeStd, Strict(), TrailingNewline()), "dr/2s)uC\r\n", false},
+		{NewEncodingWithOptions(encodeStd, Strict()).WithWrap(4), "dr/2\ns)uC", true},
+		{NewEncodingWithOptions(encodeStd, IgnoreChars(" "), Strict()), "dr/2 s)uC", false},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := tc.enc.DecodeString(tc.input)
+			if tc.ok && err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if !tc.ok && err == nil {
+				t.Errorf("Expected decoding error, got nil")
+			}
+		})
+	}
+}
+
+func TestStrictLayout(t *testing.T) {
+	wrapped := NewEncodingWithOptions(encodeStd, Strict(), Wrap(4), TrailingNewline())
+	grouped := NewEncodingWithOptions(encodeStd, Strict(), Group(4, ' '), TrailingNewline())
+	cli := NewEncodingWithOptions(encodeStd, Strict(), Wrap(76), TrailingNewline())
+	cases := []struct {
+		enc    *Encoding
+		input  string
+		offset int64 // Offset of the expected CorruptInputError, or -1 for none.
+	}{
+		{wrapped, "", -1},
+		{wrapped, "dr/2\ns)uC\n", -1},
+		{wrapped, "dr/2\nY\n", -1},
+		{wrapped, "dr/2\ns)uC", 8},     // No trailing newline.
+		{wrapped, "dr/2\n\ns)uC\n", 5}, // Empty line.
+		{wrapped, "\ndr/2\ns)uC\n", 0}, // Leading empty line.
+		{wrapped, "dr/2\ns)uC\n\n", 10},
+		{wrapped, "dr/2s)uC\n", 4},   // Line too long.
+		{wrapped, "dr/\n2s)uC\n", 4}, // Line too short.
+		{wrapped, "\n", 0},
+		{grouped, "dr/2 s)uC\n", -1},
+		{grouped, "dr/2 s)uC", 8},
+		{grouped, "dr/2 s)uC \n", 10},
+		{grouped, "dr/2  s)uC\n", 5},
+		{cli, "TPwJh>\nIo2\n\n\nTv!lE\n", 7},
+		{cli, "TPwJh>\nIo2\n\n\nTv!lE", 7},
+		{cli, "\nTPwJh>Io2Tv!lE\n", 0},
+		{cli, "TPwJh>Io2Tv!lE\n\n", 15},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			checkOffset := func(what string, err error) {
+				t.Helper()
+				if tc.offset < 0 && err != nil {
+					t.Errorf("%s: got decoding error: %v", what, err)
+				} else if e, ok := err.(CorruptInputError); tc.offset >= 0 && (!ok || e.Offset != tc.offset) {
+					t.Errorf("%s: expected CorruptInputError at offset %d, got %v", what, tc.offset, err)
+				}
+			}
+
+			_, err := tc.enc.DecodeString(tc.input)
+			checkOffset("Decode", err)
+			_, err = io.ReadAll(NewDecoder(tc.enc, strings.NewReader(tc.input)))
+			checkOffset("NewDecoder", err)
+			_, err = tc.enc.DecodeSecret([]byte(tc.input))
+			checkOffset("DecodeSecret", err)
+			errs := tc.enc.Validate([]byte(tc.input))
+			if len(errs) > 0 {
+				err = errs[0]
+			} else {
+				err = nil
+			}
+			checkOffset("Validate", err)
+			if got := tc.enc.IsCanonical([]byte(tc.input)); got != (tc.offset < 0) {
+				t.Errorf("Expected IsCanonical %v, got %v", tc.offset < 0, got)
+			}
+		})
+	}
+}
+
+func TestStrictFinalGroup(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	cases := []struct {
+		input  string
+		offset int64 // Offset of the expected CorruptInputError, or -1 for none.
+	}{
+		{"", -1},
+		{"dr/2s)uC", -1},
+		{"dr.J", -1},
+		{"A", 0},      // A lone symbol cannot complete a byte by itself.
+		{"dr.:", 2},   // Non-zero padding bits in the final pair.
+		{"L\"", 0},    // Non-zero padding bits in the only pair.
+		{"dr\"", 2},   // Lone symbol with bits beyond the final byte.
+		{"dr.J~~", 4}, // Final pair with bits beyond the final byte.
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if _, err := StdEncoding.DecodeString(tc.input); err != nil {
+				t.Fatalf("Lenient decoding failed: %v", err)
+			}
+			_, err := strict.DecodeString(tc.input)
+			if tc.offset < 0 && err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if e, ok := err.(CorruptInputError); tc.offset >= 0 && (!ok || e.Offset != tc.offset) {
+				t.Errorf("Expected CorruptInputError at offset %d, got %v", tc.offset, err)
+			}
+			if want := StdEncoding.IsCanonical([]byte(tc.input)); want != (err == nil) {
+				t.Errorf("Strict decoding and IsCanonical disagree: error %v, IsCanonical %v", err, want)
+			}
+		})
+	}
+}
+
+func TestInvalidBytePolicy(t *testing.T) {
+	cases := []struct {
+		enc     *Encoding
+		input   string
+		decoded string
+		name    string
+	}{
+		{NewEncodingWithOptions(encodeStd, SkipInvalid()), "dr/2 s)-uC\n", "foobar", "base91/std,skip-invalid"},
+		{NewEncodingWithOptions(encodeStd, SkipInvalid()).WithWrap(4), "d'r/2s)\\uC", "foobar", "base91/std,wrap=4,skip-invalid"},
+		{NewEncodingWithOptions(encodeStd, ReplaceInvalid('s')), "dr/2-)uC", "foobar", "base91/std,replace-invalid='s'"},
+		{NewEncodingWithOptions(encodeStd, ReplaceInvalid('A')), "dr/2s)u\nC", "foobar", "base91/std,replace-invalid='A'"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := tc.enc.String(); got != tc.name {
+				t.Errorf("Expected name %q, got %q", tc.name, got)
+			}
+			if !tc.enc.ValidString(tc.input) {
+				t.Errorf("Expected %q to be valid", tc.input)
+			}
+			got, err := tc.enc.DecodeString(tc.input)
+			if err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if string(got) != tc.decoded {
+				t.Errorf("Expected %q, got %q", tc.decoded, got)
+			}
+		})
+	}
+
+	strict := NewEncodingWithOptions(encodeStd, SkipInvalid(), Strict())
+	if _, err := strict.DecodeString("dr/2 s)uC"); err == nil {
+		t.Errorf("Expected Strict to override SkipInvalid")
+	}
+
+	replaced := NewEncodingWithOptions(encodeStd, ReplaceInvalid('A'))
+	if replaced.IsCanonical([]byte("AA-")) {
+		t.Errorf("Expected input with a replaced byte not to be canonical")
+	}
+	clone, err := replaced.Clone(map[byte]byte{'A': '-'})
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if got := clone.String(); !strings.HasSuffix(got, ",replace-invalid='-'") {
+		t.Errorf("Expected replacement to follow the cloned alphabet, got name %q", got)
+	}
+}
+
+func TestMaxDecodedLen(t *testing.T) {
+	enc := NewEncodingWithOptions(encodeStd, MaxDecodedLen(6))
+	if got, want := enc.String(), "base91/std,max=6"; got != want {
+		t.Errorf("Expected name %q, got %q", want, got)
+	}
+
+	cases := []struct {
+		input string
+		err   error
+	}{
+		{"dr/2s)uC", nil}, // Exactly 6 bytes.
+		{"dr/2s)uCAA", ErrTooLarge},
+		{"dr/2s)u-AA", ErrCorruptInput},
+		{"dr/2s)\nuC\n", nil}, // Line breaks inflate DecodedLen but not the result.
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := enc.DecodeString(tc.input)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("DecodeString: expected %v, got %v", tc.err, err)
+			}
+			_, err = enc.Normalize([]byte(tc.input))
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Normalize: expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	huge := strings.Repeat("A", 1<<20)
+	if n := testing.AllocsPerRun(10, func() { enc.DecodeString(huge) }); n != 0 {
+		t.Errorf("Expected no allocations for oversized input, got %v", n)
+	}
+}
+
+func TestEncodeTable(t *testing.T) {
+	cases := []*Encoding{
+		NewEncodingWithOptions(encodeStd, EncodeTable()),
+		NewEncodingWithOptions(encodeStd, EncodeTable(), Wrap(7), TrailingNewline()),
+	}
+
+	src := benchmarkData(1000)
+	for i, enc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			plain := NewEncodingWithOptions(enc.Alphabet())
+			plain.wrap, plain.trailingNewline = enc.wrap, enc.trailingNewline
+			for n := 0; n <= len(src); n += 37 {
+				want := plain.EncodeToString(src[:n])
+				if got := enc.EncodeToString(src[:n]); got != want {
+					t.Fatalf("EncodeToString of %d bytes: expected %q, got %q", n, want, got)
+				}
+				var b strings.Builder
+				enc.EncodeToBuilder(&b, src[:n])
+				if got := b.String(); got != want {
+					t.Fatalf("EncodeToBuilder of %d bytes: expected %q, got %q", n, want, got)
+				}
+			}
+		})
+	}
+
+	clone, err := cases[0].Clone(map[byte]byte{'"': '-'})
+	if err != nil {
+		t.Fatalf("Got clone error: %v", err)
+	}
+	plainClone, _ := NewEncodingStrict(clone.Alphabet())
+	if got, want := clone.EncodeToString(src), plainClone.EncodeToString(src); got != want {
+		t.Errorf("Expected clone to use its own alphabet: expected %q, got %q", want, got)
+	}
+}
+
+func TestDecodeTable(t *testing.T) {
+	cases := [][]Option{
+		nil,
+		{Strict()},
+		{Strict(), Wrap(5)},
+		{IgnoreChars(" ")},
+		{SkipInvalid()},
+		{ReplaceInvalid('A')},
+	}
+
+	encoded := StdEncoding.EncodeToString(benchmarkData(200))
+	inputs := []string{
+		"",
+		"A",
+		"dr/2s)uC",
+		"dr/2s)uC\n",
+		"dr/\n2s)\nuC",
+		"d r/2 s)u C",
+		"dr/2-s)uC",
+		"dr/2s)uC-",
+		"dr.JAA",
+		encoded,
+		encoded[:101] + "\r\n" + encoded[101:],
+	}
+
+	for i, opts := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			plain := NewEncodingWithOptions(encodeStd, opts...)
+			enc := NewEncodingWithOptions(encodeStd, append(opts, DecodeTable())...)
+			for _, input := range inputs {
+				want, wantErr := plain.DecodeString(input)
+				got, err := enc.DecodeString(input)
+				if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+					t.Errorf("Input %q: expected error %v, got %v", input, wantErr, err)
+				} else if string(got) != string(want) {
+					t.Errorf("Input %q: expected %q, got %q", input, want, got)
+				}
+			}
+		})
+	}
+
+	clone, err := NewEncodingWithOptions(encodeStd, DecodeTable()).Clone(map[byte]byte{'"': '-'})
+	if err != nil {
+		t.Fatalf("Got clone error: %v", err)
+	}
+	if _, err := clone.DecodeString("dr/2-s)uC"); err != nil {
+		t.Errorf("Expected clone to decode its own alphabet, got error: %v", err)
+	}
+	if _, err := clone.DecodeString("dr/2\"s)uC"); err == nil {
+		t.Errorf("Expected clone to reject a replaced symbol, got nil")
+	}
+
+	// Copies that only change options share the table of the original.
+	enc := NewEncodingWithOptions(encodeStd, Strict(), DecodeTable())
+	if enc.WithWrap(10).decodePairs != enc.decodePairs {
+		t.Errorf("Expected WithWrap to share the decode table")
+	}
+	if e := NewMIMEDecoder(enc, nil).(*streamDecoder).enc; e.decodePairs != enc.decodePairs {
+		t.Errorf("Expected NewMIMEDecoder to share the decode table")
+	}
+}
+
+func TestStrictHelpersRoundTrip(t *testing.T) {
+	// The helpers encode without wrapping whatever the options of the Encoding,
+	// and must decode their own output even when it is strict.
+	encs := []*Encoding{
+		NewEncodingWithOptions(encodeStd, Strict(), Wrap(10)),
+		NewEncodingWithOptions(encodeStd, Strict(), Group(4, '-')),
+		NewEncodingWithOptions(encodeStd, Strict(), TrailingNewline()),
+		NewEncodingWithOptions(encodeStd, Strict(), Wrap(10), TrailingNewline()),
+	}
+	data := []byte("The quick brown fox jumps over the lazy dog, twice: the quick brown fox.")
+	var b64 [64]byte
+	copy(b64[:], data)
+	var b32 [32]byte
+	copy(b32[:], data)
+	var b16 [16]byte
+	copy(b16[:], data)
+
+	for i, enc := range encs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			check := func(what string, got []byte, err error, want []byte) {
+				t.Helper()
+				if err != nil {
+					t.Errorf("%s: got decoding error: %v", what, err)
+				} else if !bytes.Equal(got, want) {
+					t.Errorf("%s: expected %q, got %q", what, want, got)
+				}
+			}
+
+			dst16, n := enc.Encode16(b16)
+			got16, err := enc.Decode16(dst16[:n])
+			check("Decode16", got16[:], err, b16[:])
+			dst32, n := enc.Encode32(b32)
+			got32, err := enc.Decode32(dst32[:n])
+			check("Decode32", got32[:], err, b32[:])
+			dst64, n := enc.Encode64(b64)
+			got64, err := enc.Decode64(dst64[:n])
+			check("Decode64", got64[:], err, b64[:])
+
+			u, err := enc.DecodeUUID(enc.EncodeUUID(b16))
+			check("DecodeUUID", u[:], err, b16[:])
+			if v, err := enc.DecodeUint64(enc.EncodeUint64(1 << 60)); err != nil || v != 1<<60 {
+				t.Errorf("DecodeUint64: expected %d, got %d (error: %v)", uint64(1<<60), v, err)
+			}
+
+			got, err := enc.DecodeHTTPHeader(enc.EncodeHTTPHeader(data))
+			check("DecodeHTTPHeader", got, err, data)
+			got, err = enc.DecodeQueryParam(enc.EncodeQueryParam(data))
+			check("DecodeQueryParam", got, err, data)
+			_, got, err = enc.DecodeDataURI(enc.EncodeDataURI("text/plain", data))
+			check("DecodeDataURI", got, err, data)
+			got, err = enc.DecodeParallel(enc.EncodeParallel(data, 16, 2), 2)
+			check("DecodeParallel", got, err, data)
+
+			var buf bytes.Buffer
+			if err := WriteRecord(&buf, enc, data); err != nil {
+				t.Fatalf("WriteRecord: %v", err)
+			}
+			got, err = ReadRecord(bufio.NewReader(&buf), enc)
+			check("ReadRecord", got, err, data)
+
+			buf.Reset()
+			fw := NewFrameWriter(enc, &buf, 16, true)
+			fw.Write(data)
+			if err := fw.Close(); err != nil {
+				t.Fatalf("FrameWriter: %v", err)
+			}
+			stream := buf.Bytes()
+			got, err = io.ReadAll(NewFrameReader(enc, bytes.NewReader(stream)))
+			check("FrameReader", got, err, data)
+			idx, err := IndexFrames(bytes.NewReader(stream))
+			if err != nil {
+				t.Fatalf("IndexFrames: %v", err)
+			}
+			got, err = io.ReadAll(NewFrameSeeker(enc, bytes.NewReader(stream), idx))
+			check("FrameSeeker", got, err, data)
+
+			buf.Reset()
+			aw := NewArmorWriter(enc, &buf, nil)
+			aw.Write(data)
+			if err := aw.Close(); err != nil {
+				t.Fatalf("ArmorWriter: %v", err)
+			}
+			block, err := NewArmorReader(enc, &buf).Next()
+			if err != nil {
+				t.Errorf("ArmorReader: got decoding error: %v", err)
+			} else {
+				check("ArmorReader", block.Bytes, nil, data)
+			}
+
+			c := enc.Codec()
+			cbuf := make([]byte, c.EncodedLen(len(data)))
+			c.Encode(cbuf, data)
+			cdst := make([]byte, c.DecodedLen(len(cbuf)))
+			n, err = c.Decode(cdst, cbuf)
+			check("Codec", cdst[:n], err, data)
+			cdst = make([]byte, c.DecodedLen(len(cbuf)))
+			n, err = c.Decode(cdst, []byte(c.EncodeToString(data)))
+			check("Codec of EncodeToString", cdst[:n], err, data)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package base91
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// An OrderedEncoding is an order-preserving variant of base91: encoded values
+// compare, as byte strings, in the same order as the data they encode, so they
+// can be used directly as ordered keys in stores such as LevelDB and Bigtable.
+// Plain base91 cannot do this, since it takes its 13- or 14-bit groups from the
+// low bits of its input first and chooses the group size from the data.
+//
+// An OrderedEncoding instead takes groups of exactly 13 bits, most significant
+// bit first, and writes each as a pair of symbols, most significant first, from
+// an alphabet in ascending byte order. The last group is padded with zero bits.
+// Since 8 bits of padding could hide a whole zero byte, the encoding of data
+// that ends in the same group as the encoding of one byte less is followed by
+// the lowest symbol of the alphabet, which tells the two apart and keeps the
+// shorter one first. Output is barely longer than that of StdEncoding, whose
+// pairs carry just over 13 bits on average.
+type OrderedEncoding struct {
+	encode    [91]byte
+	decodeMap [256]byte
+}
+
+// StdOrderedEncoding is the order-preserving encoding whose alphabet is that of
+// StdEncoding, sorted.
+var StdOrderedEncoding = mustOrdered(encodeStd)
+
+// NewOrderedEncoding returns a new OrderedEncoding defined by the given
+// alphabet, which must consist of 91 distinct bytes. The alphabet is sorted, so
+// its order does not matter, and encodings whose alphabets contain the same
+// bytes are identical. It returns an error if the alphabet is invalid.
+func NewOrderedEncoding(alphabet string) (*OrderedEncoding, error) {
+	if len(alphabet) != 91 {
+		return nil, errors.New("encoding alphabet is " + strconv.Itoa(len(alphabet)) + " bytes long, not 91")
+	}
+
+	e := new(OrderedEncoding)
+	copy(e.encode[:], alphabet)
+	sort.Slice(e.encode[:], func(i, j int) bool { return e.encode[i] < e.encode[j] })
+	for i := range e.decodeMap {
+		e.decodeMap[i] = 0xff
+	}
+	for i, c := range e.encode {
+		if i > 0 && c == e.encode[i-1] {
+			return nil, errors.New("encoding alphabet contains " + strconv.QuoteRune(rune(c)) + " more than once")
+		}
+		e.decodeMap[c] = byte(i)
+	}
+	return e, nil
+}
+
+// mustOrdered is like NewOrderedEncoding but panics if the alphabet is invalid.
+func mustOrdered(alphabet string) *OrderedEncoding {
+	e, err := NewOrderedEncoding(alphabet)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns a short description of enc for use in messages.
+func (enc *OrderedEncoding) String() string {
+	return "base91/ordered"
+}
+
+// orderedGroups returns the number of 13-bit groups that n bytes take.
+func orderedGroups(n int) int {
+	return (8*n + 12) / 13
+}
+
+// orderedMarked reports whether the encoding of n bytes ends with a marker
+// symbol, which it does if n-1 bytes take the same number of groups.
+func orderedMarked(n int) bool {
+	return n > 1 && orderedGroups(n-1) == orderedGroups(n)
+}
+
+// EncodedLen returns the length in bytes of the encoding of n bytes of data.
+// Unlike that of an Encoding, it is exact.
+func (enc *OrderedEncoding) EncodedLen(n int) int {
+	size := 2 * orderedGroups(n)
+	if orderedMarked(n) {
+		size++
+	}
+	return size
+}
+
+// Encode encodes src using the encoding enc, writing EncodedLen(len(src))
+// bytes to dst, and returns the number of bytes written. If dst is too short,
+// Encode writes nothing and returns ErrShortDst.
+func (enc *OrderedEncoding) Encode(dst, src []byte) (int, error) {
+	size := enc.EncodedLen(len(src))
+	if len(dst) < size {
+		return 0, ErrShortDst
+	}
+
+	var queue, numBits uint32
+	n := 0
+	for _, c := range src {
+		queue = queue<<8 | uint32(c)
+		numBits += 8
+		if numBits >= 13 {
+			numBits -= 13
+			enc.putPair(dst[n:], queue>>numBits)
+			queue &= 1<<numBits - 1
+			n += 2
+		}
+	}
+	if numBits > 0 {
+		enc.putPair(dst[n:], queue<<(13-numBits))
+		n += 2
+	}
+	if n < size {
+		dst[n] = enc.encode[0]
+		n++
+	}
+	return n, nil
+}
+
+// putPair writes the symbol pair for the 13-bit value v to dst[0] and dst[1],
+// most significant symbol first.
+func (enc *OrderedEncoding) putPair(dst []byte, v uint32) {
+	dst[0] = enc.encode[v/91]
+	dst[1] = enc.encode[v%91]
+}
+
+// EncodeToString returns the encoding of src using enc.
+func (enc *OrderedEncoding) EncodeToString(src []byte) string {
+	buf := make([]byte, enc.EncodedLen(len(src)))
+	enc.Encode(buf, src)
+	return string(buf)
+}
+
+// DecodedLen returns the maximum length in bytes of the data that n bytes of
+// encoded data decode to.
+func (enc *OrderedEncoding) DecodedLen(n int) int {
+	return 13 * (n / 2) / 8
+}
+
+// Decode decodes src using the encoding enc, writing to dst, and returns the
+// number of bytes written. Since encoded values are meant to be compared, each
+// value has exactly one encoding: Decode returns a CorruptInputError for any
+// byte outside the alphabet, including line breaks, for a pair that does not
+// stand for a 13-bit value, for non-zero padding bits, and for a misplaced
+// marker symbol. If dst is too short, it returns ErrShortDst.
+func (enc *OrderedEncoding) Decode(dst, src []byte) (int, error) {
+	g := len(src) / 2
+	size := 13 * g / 8
+	if len(src)%2 == 0 {
+		if g > 0 && orderedGroups(size-1) == g {
+			// size bytes would have been marked, so this is one byte less.
+			size--
+		}
+	} else if !orderedMarked(size) || src[len(src)-1] != enc.encode[0] {
+		return 0, newCorruptInputError(src, len(src)-1, enc.String())
+	}
+	if len(dst) < size {
+		return 0, ErrShortDst
+	}
+
+	var queue, numBits uint32
+	n := 0
+	for i := 0; i+1 < len(src); i += 2 {
+		hi, lo := enc.decodeMap[src[i]], enc.decodeMap[src[i+1]]
+		if hi == 0xff {
+			return n, newCorruptInputError(src, i, enc.String())
+		}
+		if lo == 0xff {
+			return n, newCorruptInputError(src, i+1, enc.String())
+		}
+		v := uint32(hi)*91 + uint32(lo)
+		if v >= 1<<13 {
+			return n, newCorruptInputError(src, i, enc.String())
+		}
+
+		queue = queue<<13 | v
+		numBits += 13
+		for numBits >= 8 && n < size {
+			numBits -= 8
+			dst[n] = byte(queue >> numBits)
+			queue &= 1<<numBits - 1
+			n++
+		}
+		if n == size && queue != 0 {
+			// The padding bits are not zero.
+			return n, newCorruptInputError(src, i, enc.String())
+		}
+	}
+	return n, nil
+}
+
+// DecodeString returns the bytes represented by the string s, which was
+// encoded with enc.
+func (enc *OrderedEncoding) DecodeString(s string) ([]byte, error) {
+	dbuf := make([]byte, enc.DecodedLen(len(s)))
+	n, err := enc.Decode(dbuf, stringBytes(s))
+	return dbuf[:n], err
+}
@@ -0,0 +1,110 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestOrderedEncodingRoundTrip(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		data := benchmarkData(n)
+		s := StdOrderedEncoding.EncodeToString(data)
+		if len(s) != StdOrderedEncoding.EncodedLen(n) {
+			t.Errorf("n=%d: expected %d bytes, got %d", n, StdOrderedEncoding.EncodedLen(n), len(s))
+		}
+		if StdOrderedEncoding.DecodedLen(len(s)) < n {
+			t.Errorf("n=%d: DecodedLen(%d) = %d", n, len(s), StdOrderedEncoding.DecodedLen(len(s)))
+		}
+		decoded, err := StdOrderedEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("n=%d: got decoding error: %v", n, err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("n=%d: expected %x, got %x", n, data, decoded)
+		}
+	}
+}
+
+func TestOrderedEncodingOrder(t *testing.T) {
+	// Short values made of few distinct bytes, so that prefixes, trailing
+	// zeros, and values that differ only in padding are all well covered.
+	var values [][]byte
+	for n := 0; n <= 4; n++ {
+		for i := 0; i < 1<<(2*n); i++ {
+			v := make([]byte, n)
+			for j := range v {
+				v[j] = []byte{0x00, 0x01, 0x80, 0xff}[i>>(2*j)&3]
+			}
+			values = append(values, v)
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		v := make([]byte, rng.Intn(20))
+		rng.Read(v)
+		values = append(values, v)
+	}
+
+	sort.Slice(values, func(i, j int) bool { return bytes.Compare(values[i], values[j]) < 0 })
+	for i := 1; i < len(values); i++ {
+		a := StdOrderedEncoding.EncodeToString(values[i-1])
+		b := StdOrderedEncoding.EncodeToString(values[i])
+		if c := bytes.Compare(values[i-1], values[i]); c == 0 && a != b || c < 0 && a >= b {
+			t.Errorf("%x < %x, but %q >= %q", values[i-1], values[i], a, b)
+		}
+	}
+}
+
+func TestOrderedEncodingAlphabet(t *testing.T) {
+	shuffled := []byte(encodeStd)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	enc, err := NewOrderedEncoding(string(shuffled))
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	if *enc != *StdOrderedEncoding {
+		t.Errorf("Expected the alphabet order not to matter")
+	}
+
+	for _, alphabet := range []string{encodeStd[:90], encodeStd[:90] + "A"} {
+		if _, err := NewOrderedEncoding(alphabet); err == nil {
+			t.Errorf("%q: expected error, got none", alphabet)
+		}
+	}
+}
+
+func TestOrderedEncodingInvalid(t *testing.T) {
+	valid := StdOrderedEncoding.EncodeToString([]byte("hello"))
+	single := StdOrderedEncoding.EncodeToString([]byte{1})
+	marked := StdOrderedEncoding.EncodeToString([]byte{1, 0, 0})
+	cases := []struct {
+		s      string
+		offset int64
+	}{
+		{valid[:len(valid)-1] + "\n", int64(len(valid) - 1)},
+		{"~~", 0},                       // 90*91+90 is not a 13-bit value.
+		{valid[:len(valid)-1] + "~", 6}, // Non-zero padding.
+		{single + "!", 2},               // No marker is due.
+		{marked[:len(marked)-1] + "#", int64(len(marked) - 1)},
+		{"!", 0},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := StdOrderedEncoding.DecodeString(tc.s)
+			var e CorruptInputError
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected CorruptInputError, got %v", err)
+			}
+			if e.Offset != tc.offset {
+				t.Errorf("Expected offset %d, got %d", tc.offset, e.Offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// EncodeParallel returns the framed base91 encoding of src, encoding it with
+// up to workers goroutines, or GOMAXPROCS goroutines if workers is not
+// positive.
+//
+// Ordinary base91 output cannot be split up, because the encoder's bit queue
+// carries over from each byte to the next across the whole message. In the
+// framed format the input is instead cut into frames of frameSize bytes (the
+// last may be shorter), each frame is encoded on its own, as if by
+// EncodeToString, and the encoded frames are joined with '\n'. Framed output
+// is not the same as the output of EncodeToString and must be decoded with
+// DecodeParallel or one line at a time. Wrapping and trailing newline settings
+// of enc do not apply to it. frameSize must be positive.
+func (enc *Encoding) EncodeParallel(src []byte, frameSize, workers int) []byte {
+	if frameSize <= 0 {
+		panic("frame size is not positive")
+	}
+
+	frames := make([][]byte, (len(src)+frameSize-1)/frameSize)
+	runParallel(len(frames), workers, func(i int) {
+		chunk := src[i*frameSize:]
+		if len(chunk) > frameSize {
+			chunk = chunk[:frameSize]
+		}
+		buf := make([]byte, maxEncodedLen(len(chunk)))
+		n, _ := enc.encode91(buf, chunk)
+		frames[i] = buf[:n]
+	})
+	return bytes.Join(frames, newline)
+}
+
+// DecodeParallel returns the bytes represented by src, which is in the framed
+// format produced by EncodeParallel, decoding its frames with up to workers
+// goroutines, or GOMAXPROCS goroutines if workers is not positive. Each line
+// of src is decoded on its own, without wrapping, so the frame size does
+// not need to be known. If src contains invalid data, DecodeParallel returns a
+// CorruptInputError for the first problem, with its offset in src.
+func (enc *Encoding) DecodeParallel(src []byte, workers int) ([]byte, error) {
+	frames := bytes.Split(src, newline)
+
+	// Find where each frame starts in src and in the output, so that every
+	// frame can be decoded straight into its place in the result. Counting the
+	// decoded length of a frame means reading all of it, so that is done in
+	// parallel too.
+	starts := make([]int, len(frames))
+	for i := 1; i < len(frames); i++ {
+		starts[i] = starts[i-1] + len(frames[i-1]) + 1
+	}
+	offsets := make([]int, len(frames)+1)
+	errs := make([]error, len(frames))
+	runParallel(len(frames), workers, func(i int) {
+		offsets[i+1], errs[i] = enc.DecodedLenExact(frames[i])
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, enc.frameError(src, starts[i], err)
+		}
+		offsets[i+1] += offsets[i]
+	}
+	if enc.maxDecodedLen > 0 && offsets[len(frames)] > enc.maxDecodedLen {
+		return nil, ErrTooLarge
+	}
+
+	dst := make([]byte, offsets[len(frames)])
+	runParallel(len(frames), workers, func(i int) {
+		_, errs[i] = enc.decodeUnwrapped(dst[offsets[i]:offsets[i+1]], frames[i])
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, enc.frameError(src, starts[i], err)
+		}
+	}
+	return dst, nil
+}
+
+// frameError returns err, which was returned when decoding the frame at the
+// given offset in src, with any CorruptInputError rewritten to describe the
+// offset and context of the problem in src as a whole.
+func (enc *Encoding) frameError(src []byte, start int, err error) error {
+	var e CorruptInputError
+	if errors.As(err, &e) {
+		return enc.corruptInputError(src, start+int(e.Offset))
+	}
+	return err
+}
+
+// runParallel calls f for each integer in [0, n) using up to workers
+// goroutines, or GOMAXPROCS goroutines if workers is not positive, and returns
+// once every call has returned.
+func runParallel(n, workers int, f func(int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	var next int64 = -1
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := atomic.AddInt64(&next, 1); i < int64(n); i = atomic.AddInt64(&next, 1) {
+				f(int(i))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,94 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncodeParallel(t *testing.T) {
+	data := benchmarkData(1000)
+	cases := []struct {
+		n         int
+		frameSize int
+		workers   int
+	}{
+		{0, 10, 4},
+		{1, 10, 4},
+		{10, 10, 4},
+		{11, 10, 4},
+		{1000, 64, 0},
+		{1000, 64, 1},
+		{1000, 999, 3},
+		{1000, 1000, 3},
+		{1000, 5000, 3},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			src := data[:tc.n]
+			encoded := StdEncoding.EncodeParallel(src, tc.frameSize, tc.workers)
+
+			var want []string
+			for start := 0; start < len(src); start += tc.frameSize {
+				end := start + tc.frameSize
+				if end > len(src) {
+					end = len(src)
+				}
+				want = append(want, StdEncoding.EncodeToString(src[start:end]))
+			}
+			if got := string(encoded); got != strings.Join(want, "\n") {
+				t.Errorf("Expected %q, got %q", strings.Join(want, "\n"), got)
+			}
+
+			decoded, err := StdEncoding.DecodeParallel(encoded, tc.workers)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, src) {
+				t.Errorf("Expected %x, got %x", src, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeParallelError(t *testing.T) {
+	encoded := StdEncoding.EncodeParallel(benchmarkData(100), 10, 0)
+	for _, i := range []int{0, 5, 20, 40, len(encoded) - 1} {
+		broken := append([]byte(nil), encoded...)
+		broken[i] = '-'
+		_, err := StdEncoding.DecodeParallel(broken, 0)
+		want := StdEncoding.corruptInputError(broken, i)
+		if err != want {
+			t.Errorf("Expected %v, got %v", want, err)
+		}
+	}
+
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	framed := []byte("dr/2s)uC\ndr.:")
+	if _, err := strict.DecodeParallel(framed, 0); err != strict.corruptInputError(framed, 11) {
+		t.Errorf("Expected non-canonical final group in second frame to be rejected, got %v", err)
+	}
+
+	limited := NewEncodingWithOptions(encodeStd, MaxDecodedLen(99))
+	if _, err := limited.DecodeParallel(encoded, 0); err != ErrTooLarge {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+}
+
+func BenchmarkEncodeParallel(b *testing.B) {
+	src := benchmarkData(4 << 20)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.EncodeParallel(src, 64<<10, 0)
+	}
+}
+
+func BenchmarkDecodeParallel(b *testing.B) {
+	src := StdEncoding.EncodeParallel(benchmarkData(4<<20), 64<<10, 0)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		StdEncoding.DecodeParallel(src, 0)
+	}
+}
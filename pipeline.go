@@ -0,0 +1,94 @@
+package base91
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// A Stage is one step of a Pipeline other than base91 encoding. It returns a
+// writer that transforms the data written to it and writes the result to w,
+// such as a compressor or an encrypting writer. Closing the writer must write
+// any buffered data to w, and must not close w.
+type Stage func(w io.Writer) (io.WriteCloser, error)
+
+// Gzip returns a Stage that compresses data with gzip at the given level, as
+// for gzip.NewWriterLevel.
+func Gzip(level int) Stage {
+	return func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, level)
+	}
+}
+
+// A Pipeline describes the transformations applied to data before it is
+// written out as text: data is first compressed, then encrypted, then base91
+// encoded. Compress and Encrypt are optional. Keeping the stages in this order
+// matters, since encrypted and encoded data do not compress, and Pipeline takes
+// care of the order and of closing every stage.
+type Pipeline struct {
+	Compress Stage     // Optional compression stage.
+	Encrypt  Stage     // Optional encryption stage.
+	Encode   *Encoding // Encoding for the final stage; StdEncoding if nil.
+}
+
+// Writer returns a PipelineWriter that passes the data written to it through the
+// stages of p and writes the result to w. Closing it closes each stage in
+// order, from the first to the last, so that each one writes out its buffered
+// data before the next is closed; it does not close w. If the writer of any
+// stage has a Flush method, as gzip.Writer does, Flush calls it in the same
+// order. A base91 encoder cannot write out the bits it has queued until it is
+// closed, so data may still be held back after Flush.
+func (p Pipeline) Writer(w io.Writer) (*PipelineWriter, error) {
+	enc := p.Encode
+	if enc == nil {
+		enc = StdEncoding
+	}
+
+	// Build the stages from the last to the first, so that each one writes to
+	// the next.
+	stages := []io.WriteCloser{NewEncoder(enc, w)}
+	for _, s := range []Stage{p.Encrypt, p.Compress} {
+		if s == nil {
+			continue
+		}
+		sw, err := s(stages[0])
+		if err != nil {
+			return nil, err
+		}
+		stages = append([]io.WriteCloser{sw}, stages...)
+	}
+	return &PipelineWriter{stages: stages}, nil
+}
+
+// A PipelineWriter writes data through the stages of a Pipeline.
+type PipelineWriter struct {
+	stages []io.WriteCloser // First stage first.
+}
+
+// Write writes p to the first stage of the pipeline.
+func (pw *PipelineWriter) Write(p []byte) (int, error) {
+	return pw.stages[0].Write(p)
+}
+
+// Flush flushes each stage that has a Flush method, from the first to the last.
+func (pw *PipelineWriter) Flush() error {
+	for _, s := range pw.stages {
+		if f, ok := s.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes each stage, from the first to the last. It closes every stage
+// even if one fails, and returns the first error.
+func (pw *PipelineWriter) Close() error {
+	var first error
+	for _, s := range pw.stages {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,131 @@
+package base91
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// xorStage is a Stage that stands in for encryption by XORing each byte with
+// a key. It records when it is closed.
+type xorStage struct {
+	w      io.Writer
+	name   string
+	key    byte
+	closed *[]string
+}
+
+func (x *xorStage) Write(p []byte) (int, error) {
+	q := make([]byte, len(p))
+	for i, c := range p {
+		q[i] = c ^ x.key
+	}
+	return x.w.Write(q)
+}
+
+func (x *xorStage) Close() error {
+	*x.closed = append(*x.closed, x.name)
+	return nil
+}
+
+func TestPipeline(t *testing.T) {
+	data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 100))
+	var closed []string
+	xor := func(w io.Writer) (io.WriteCloser, error) {
+		return &xorStage{w: w, name: "xor", key: 0x5a, closed: &closed}, nil
+	}
+
+	cases := []struct {
+		p    Pipeline
+		want func([]byte) []byte
+	}{
+		{Pipeline{}, func(b []byte) []byte { return b }},
+		{Pipeline{Encrypt: xor}, xorBytes},
+		{Pipeline{Compress: Gzip(gzip.BestCompression)}, gunzip},
+		{Pipeline{Compress: Gzip(gzip.BestCompression), Encrypt: xor}, func(b []byte) []byte { return gunzip(xorBytes(b)) }},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			pw, err := tc.p.Writer(&buf)
+			if err != nil {
+				t.Fatalf("Got pipeline error: %v", err)
+			}
+			if _, err := pw.Write(data); err != nil {
+				t.Fatalf("Got writing error: %v", err)
+			}
+			if err := pw.Close(); err != nil {
+				t.Fatalf("Got closing error: %v", err)
+			}
+
+			raw, err := StdEncoding.DecodeString(buf.String())
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if got := tc.want(raw); !bytes.Equal(got, data) {
+				t.Errorf("Expected %q, got %q", data, got)
+			}
+		})
+	}
+}
+
+func TestPipelineClose(t *testing.T) {
+	var closed []string
+	stage := func(name string) Stage {
+		return func(w io.Writer) (io.WriteCloser, error) {
+			return &xorStage{w: w, name: name, closed: &closed}, nil
+		}
+	}
+
+	pw, _ := Pipeline{Compress: stage("compress"), Encrypt: stage("encrypt")}.Writer(io.Discard)
+	pw.Close()
+	if want := []string{"compress", "encrypt"}; strings.Join(closed, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected stages closed in order %v, got %v", want, closed)
+	}
+}
+
+func xorBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		out[i] = c ^ 0x5a
+	}
+	return out
+}
+
+func gunzip(b []byte) []byte {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+	out, _ := io.ReadAll(zr)
+	return out
+}
+
+func TestPipelineFlush(t *testing.T) {
+	var buf bytes.Buffer
+	pw, _ := Pipeline{Compress: Gzip(gzip.DefaultCompression)}.Writer(&buf)
+	pw.Write([]byte("hello"))
+	n := buf.Len()
+	if err := pw.Flush(); err != nil {
+		t.Fatalf("Got flushing error: %v", err)
+	}
+	if buf.Len() <= n {
+		t.Error("Expected more output after Flush, got none")
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	errStage := errors.New("stage failed")
+	failing := func(w io.Writer) (io.WriteCloser, error) { return nil, errStage }
+	if _, err := (Pipeline{Encrypt: failing}).Writer(io.Discard); err != errStage {
+		t.Errorf("Expected %v, got %v", errStage, err)
+	}
+	if _, err := (Pipeline{Compress: Gzip(100)}).Writer(io.Discard); err == nil {
+		t.Error("Expected error for invalid gzip level, got nil")
+	}
+}
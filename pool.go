@@ -0,0 +1,60 @@
+package base91
+
+import "sync"
+
+// A scratchBuf is a buffer kept in scratchPool. release returns it to the
+// pool; it is made once per scratchBuf so that handing it out, as
+// DecodeStringPooled does, does not allocate.
+type scratchBuf struct {
+	b       []byte
+	release func()
+}
+
+// scratchPool holds buffers that the convenience functions encode or decode
+// into before copying the result to memory of exactly the right size. That
+// way they allocate only the result, and the result has no spare capacity.
+var scratchPool sync.Pool
+
+func init() {
+	// New is set here because it refers to scratchPool itself.
+	scratchPool.New = func() interface{} {
+		p := new(scratchBuf)
+		p.release = func() { scratchPool.Put(p) }
+		return p
+	}
+}
+
+// getScratch returns a pooled buffer whose b field has length n, which must be
+// at most maxPooledLen (see lowmem.go). It should be returned to the pool by calling release.
+func getScratch(n int) *scratchBuf {
+	p := scratchPool.Get().(*scratchBuf)
+	if cap(p.b) < n {
+		p.b = make([]byte, n)
+	}
+	p.b = p.b[:n]
+	return p
+}
+
+// DecodeStringPooled is like DecodeString, but the returned buffer may come from
+// a pool shared with other calls rather than being allocated. It is meant for
+// hot paths that decode a message, parse it, and discard it. The caller must
+// call release exactly once when it is done with buf, and must not use buf
+// afterwards, since it may be handed to another caller. release is never nil,
+// even if err is not nil; buf then holds the data decoded before the error.
+func (enc *Encoding) DecodeStringPooled(s string) (buf []byte, release func(), err error) {
+	size, err := enc.decodeBufLen(stringBytes(s))
+	if err != nil {
+		return nil, noRelease, err
+	}
+	if size > maxPooledLen {
+		buf, err = enc.DecodeString(s)
+		return buf, noRelease, err
+	}
+
+	p := getScratch(size)
+	n, err := enc.DecodeStringInto(p.b, s)
+	return p.b[:n:n], p.release, err
+}
+
+// noRelease is the release function for buffers that do not belong to the pool.
+func noRelease() {}
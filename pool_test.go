@@ -0,0 +1,97 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestConvenienceFunctionsPooled(t *testing.T) {
+	for _, n := range []int{0, 1, 64, 1000, maxPooledLen, 2 * maxPooledLen} {
+		t.Run(fmt.Sprintf("len_%d", n), func(t *testing.T) {
+			src := benchmarkData(n)
+			encoded := StdEncoding.EncodeToString(src)
+			if len(encoded) != StdEncoding.EncodedLenExact(src) {
+				t.Errorf("Expected encoded length %d, got %d", StdEncoding.EncodedLenExact(src), len(encoded))
+			}
+
+			decoded, err := StdEncoding.DecodeString(encoded)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, src) {
+				t.Fatalf("Expected %x, got %x", src, decoded)
+			}
+			if StdEncoding.DecodedLen(len(encoded)) <= maxPooledLen && cap(decoded) != len(decoded) {
+				t.Errorf("Expected no spare capacity, got len %d and cap %d", len(decoded), cap(decoded))
+			}
+		})
+	}
+}
+
+func TestConvenienceFunctionsAllocs(t *testing.T) {
+	src := benchmarkData(64)
+	encoded := StdEncoding.EncodeToString(src)
+
+	if n := testing.AllocsPerRun(100, func() { StdEncoding.EncodeToString(src) }); n != 1 {
+		t.Errorf("EncodeToString: expected 1 allocation, got %v", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { StdEncoding.DecodeString(encoded) }); n != 1 {
+		t.Errorf("DecodeString: expected 1 allocation, got %v", n)
+	}
+}
+
+func TestDecodeStringPooledPartial(t *testing.T) {
+	got, err := StdEncoding.DecodeString("dr/2s)uC-")
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if string(got) != "foobar" {
+		t.Errorf("Expected the data decoded before the error, %q, got %q", "foobar", got)
+	}
+}
+
+func TestDecodeStringPooled(t *testing.T) {
+	for _, n := range []int{0, 6, 1000, 2 * maxPooledLen} {
+		src := benchmarkData(n)
+		buf, release, err := StdEncoding.DecodeStringPooled(StdEncoding.EncodeToString(src))
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if !bytes.Equal(buf, src) {
+			t.Errorf("Expected %x, got %x", src, buf)
+		}
+		release()
+	}
+
+	buf, release, err := StdEncoding.DecodeStringPooled("dr/2s)uC-")
+	if err == nil || string(buf) != "foobar" {
+		t.Errorf("Expected %q and an error, got %q and %v", "foobar", buf, err)
+	}
+	release()
+
+	limited := NewEncodingWithOptions(encodeStd, MaxDecodedLen(4))
+	if _, release, err := limited.DecodeStringPooled("dr/2s)uC"); err != ErrTooLarge || release == nil {
+		t.Errorf("Expected %v and a release function, got %v", ErrTooLarge, err)
+	}
+
+	if lowMemory {
+		return
+	}
+	encoded := StdEncoding.EncodeToString(benchmarkData(64))
+	if n := testing.AllocsPerRun(100, func() {
+		_, release, _ := StdEncoding.DecodeStringPooled(encoded)
+		release()
+	}); n != 0 {
+		t.Errorf("Expected no allocations, got %v", n)
+	}
+}
+
+func BenchmarkDecodeStringPooled(b *testing.B) {
+	src := StdEncoding.EncodeToString(benchmarkData(64))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		_, release, _ := StdEncoding.DecodeStringPooled(src)
+		release()
+	}
+}
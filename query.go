@@ -0,0 +1,88 @@
+package base91
+
+import "errors"
+
+// ErrBadEscape is returned by DecodeQueryParam when its input contains a '%'
+// that does not start a valid percent-encoded character.
+var ErrBadEscape = errors.New("invalid percent-encoding")
+
+// EncodeQueryParam returns the base91 encoding of src, without wrapping, with
+// the characters that have a special meaning in a URL query string, or may not
+// appear in one, percent-encoded, as reported by CheckAlphabetSafeFor for
+// URLQuery. The result can be placed in a query string as it is, as in
+// "?token=" + enc.EncodeQueryParam(data), and parsing the query, as with
+// url.ParseQuery, yields the plain base91 encoding of src. Since only 77
+// printable characters are safe in a query value, no base91 alphabet avoids
+// escaping entirely; with the standard alphabet, 16 of the 91 symbols are
+// escaped.
+func (enc *Encoding) EncodeQueryParam(src []byte) string {
+	buf := make([]byte, maxEncodedLen(len(src)))
+	n, _ := enc.encode91(buf, src)
+	return string(appendPercentEncoded(make([]byte, 0, n+n/2), buf[:n], URLQuery.needsEscape))
+}
+
+// DecodeQueryParam returns the bytes represented by s, the percent-encoded form
+// of a value produced by EncodeQueryParam, as it appears in a URL. A value
+// that has already been unescaped, such as one from url.Values, is plain
+// base91, and must be decoded with DecodeString instead, since '%' is a symbol
+// of the standard alphabet. If s contains an invalid escape, DecodeQueryParam
+// returns ErrBadEscape.
+func (enc *Encoding) DecodeQueryParam(s string) ([]byte, error) {
+	src, ok := percentDecode([]byte(s))
+	if !ok {
+		return nil, ErrBadEscape
+	}
+	return enc.decodeToNew(src, true)
+}
+
+const upperHexDigits = "0123456789ABCDEF"
+
+// appendPercentEncoded appends src to dst, writing each byte c for which
+// needsEscape(c) is true as '%' and two hexadecimal digits.
+func appendPercentEncoded(dst, src []byte, needsEscape func(byte) bool) []byte {
+	for _, c := range src {
+		if needsEscape(c) {
+			dst = append(dst, '%', upperHexDigits[c>>4], upperHexDigits[c&15])
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// percentDecode decodes the percent-encoded characters in src in place and
+// returns the result. It reports false if a '%' is not followed by two
+// hexadecimal digits.
+func percentDecode(src []byte) ([]byte, bool) {
+	n := 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '%' {
+			if i+2 >= len(src) {
+				return nil, false
+			}
+			hi, lo := unhex(src[i+1]), unhex(src[i+2])
+			if hi > 15 || lo > 15 {
+				return nil, false
+			}
+			c = hi<<4 | lo
+			i += 2
+		}
+		src[n] = c
+		n++
+	}
+	return src[:n], true
+}
+
+// unhex returns the value of the hexadecimal digit c, or 0xff if c is not one.
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10
+	}
+	return 0xff
+}
@@ -0,0 +1,57 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestQueryParam(t *testing.T) {
+	data := benchmarkData(1000)
+	for i, n := range []int{0, 1, 10, 1000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			v := StdEncoding.EncodeQueryParam(data[:n])
+
+			// The value parses back to the plain encoding.
+			q, err := url.ParseQuery("a=b&token=" + v + "&c=d")
+			if err != nil {
+				t.Fatalf("Got query parsing error: %v", err)
+			}
+			if got, want := q.Get("token"), StdEncoding.EncodeToString(data[:n]); got != want {
+				t.Errorf("Expected %q, got %q", want, got)
+			}
+			if q.Get("a") != "b" || q.Get("c") != "d" {
+				t.Errorf("Expected other parameters intact, got %v", q)
+			}
+
+			decoded, err := StdEncoding.DecodeQueryParam(v)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data[:n]) {
+				t.Errorf("Expected %x, got %x", data[:n], decoded)
+			}
+		})
+	}
+}
+
+func TestEncodeQueryParam(t *testing.T) {
+	// "hello" encodes to "TPwJh>A".
+	if got, want := StdEncoding.EncodeQueryParam([]byte("hello")), "TPwJh%3EA"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestDecodeQueryParamErrors(t *testing.T) {
+	for i, in := range []string{"TPwJh%3", "TPwJh%3xA", "%"} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if _, err := StdEncoding.DecodeQueryParam(in); err != ErrBadEscape {
+				t.Errorf("Expected %v, got %v", ErrBadEscape, err)
+			}
+		})
+	}
+	if _, err := StdEncoding.DecodeQueryParam("A%2DA"); err == nil {
+		t.Error("Expected error for invalid input, got nil")
+	}
+}
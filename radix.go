@@ -0,0 +1,230 @@
+package base91
+
+import (
+	"errors"
+	"strconv"
+)
+
+// A RadixEncoding is an encoding that uses the base91 algorithm with an
+// alphabet of some other size. Base91 packs 13 or 14 bits into each pair of
+// symbols: 13 bits always fit, since 91*91 = 8281 is at least 2^13, and a group
+// whose low 13 bits are below 8281 - 2^13 = 89 can take a 14th bit without
+// reaching 8281. For an alphabet of r symbols, a pair likewise carries b or b+1
+// bits, where b is the largest number of bits that always fits, with 2^b <=
+// r*r, and the threshold for the extra bit is r*r - 2^b. A smaller alphabet
+// leaves out more troublesome characters at the cost of slightly longer
+// output; with 85 symbols, output is only about 2% longer than base91.
+//
+// A RadixEncoding with a 91-symbol alphabet produces the same output as an
+// Encoding with that alphabet, though more slowly. Decoding ignores '\r' and
+// '\n'.
+//
+// RadixEncoding has its own copy of the bit queue rather than sharing the one
+// in Encoding. The Encoding code is built around the fixed 13- and 14-bit
+// groups of base91: its pair tables, word-at-a-time loops, and bounds on
+// encoded and decoded lengths all assume them, and turning the group width
+// and threshold into fields would slow down every Encoding for the sake of
+// this rarely used type. The copy here is the plain byte-at-a-time loop, kept
+// short so that it is easy to check against the original.
+type RadixEncoding struct {
+	encode    []byte
+	decodeMap [256]byte
+	radix     uint32
+	bits      uint32 // Number of bits that a symbol pair always carries.
+	mask      uint32 // 1<<bits - 1.
+	threshold uint32 // A pair carries one more bit if its low bits are below this.
+	name      string
+}
+
+// Smallest and largest alphabet sizes that NewEncodingRadix accepts. With 85
+// symbols, as in base85, output is only about 2% longer than base91, and
+// there are only 94 printable ASCII characters other than space, so sizes
+// outside this range give up either the density or the printability that
+// base91 is for.
+const (
+	minRadix = 85
+	maxRadix = 94
+)
+
+// NewEncodingRadix returns a new RadixEncoding defined by the given alphabet,
+// which must be from 85 to 94 bytes long, must not contain CR or LF ('\r',
+// '\n'), and must not contain the same byte more than once. It returns an
+// error if the alphabet is invalid.
+func NewEncodingRadix(alphabet string) (*RadixEncoding, error) {
+	r := len(alphabet)
+	if r < minRadix || r > maxRadix {
+		return nil, errors.New("encoding alphabet is " + strconv.Itoa(r) + " bytes long, not from " +
+			strconv.Itoa(minRadix) + " to " + strconv.Itoa(maxRadix))
+	}
+
+	e := &RadixEncoding{encode: []byte(alphabet), radix: uint32(r)}
+	for i := range e.decodeMap {
+		e.decodeMap[i] = 0xff
+	}
+	e.decodeMap['\n'] = 0xfe
+	e.decodeMap['\r'] = 0xfe
+	for i := 0; i < r; i++ {
+		c := alphabet[i]
+		if c == '\n' || c == '\r' {
+			return nil, errors.New("encoding alphabet contains newline character at index " + strconv.Itoa(i))
+		}
+		if e.decodeMap[c] != 0xff {
+			return nil, errors.New("encoding alphabet contains " + strconv.QuoteRune(rune(c)) + " more than once")
+		}
+		e.decodeMap[c] = byte(i)
+	}
+
+	square := e.radix * e.radix
+	for 1<<(e.bits+1) <= square {
+		e.bits++
+	}
+	e.mask = 1<<e.bits - 1
+	e.threshold = square - 1<<e.bits
+	e.name = "base91/radix=" + strconv.Itoa(r)
+	return e, nil
+}
+
+// Radix returns the number of symbols in the alphabet of enc.
+func (enc *RadixEncoding) Radix() int {
+	return int(enc.radix)
+}
+
+// String returns a short description of enc for use in messages, such as
+// "base91/radix=85".
+func (enc *RadixEncoding) String() string {
+	return enc.name
+}
+
+// Encode encodes src using the encoding enc, writing to dst, and returns the
+// number of bytes written. If dst is too short, Encode returns the number of
+// bytes written and ErrShortDst. A dst of EncodedLen(len(src)) bytes is always
+// long enough.
+func (enc *RadixEncoding) Encode(dst, src []byte) (int, error) {
+	var queue, numBits uint32
+	n := 0
+	for _, c := range src {
+		queue |= uint32(c) << numBits
+		numBits += 8
+		if numBits > enc.bits {
+			v := queue & enc.mask
+			if v < enc.threshold {
+				// The pair can take one more bit.
+				v = queue & (enc.mask<<1 | 1)
+				queue >>= enc.bits + 1
+				numBits -= enc.bits + 1
+			} else {
+				queue >>= enc.bits
+				numBits -= enc.bits
+			}
+			if n+2 > len(dst) {
+				return n, ErrShortDst
+			}
+			dst[n] = enc.encode[v%enc.radix]
+			dst[n+1] = enc.encode[v/enc.radix]
+			n += 2
+		}
+	}
+
+	if numBits > 0 {
+		if numBits > 7 || queue >= enc.radix {
+			if n+2 > len(dst) {
+				return n, ErrShortDst
+			}
+			dst[n] = enc.encode[queue%enc.radix]
+			dst[n+1] = enc.encode[queue/enc.radix]
+			n += 2
+		} else {
+			if n+1 > len(dst) {
+				return n, ErrShortDst
+			}
+			dst[n] = enc.encode[queue]
+			n++
+		}
+	}
+	return n, nil
+}
+
+// EncodeToString returns the encoding of src using enc.
+func (enc *RadixEncoding) EncodeToString(src []byte) string {
+	buf := make([]byte, enc.EncodedLen(len(src)))
+	n, _ := enc.Encode(buf, src)
+	return string(buf[:n])
+}
+
+// EncodedLen returns an upper bound on the length in bytes of the encoding of
+// n bytes of data.
+func (enc *RadixEncoding) EncodedLen(n int) int {
+	if n == 0 {
+		return 0
+	}
+	// Every pair but the last carries at least enc.bits bits.
+	return 2*(8*n/int(enc.bits)) + 2
+}
+
+// Decode decodes src using the encoding enc, writing to dst, and returns the
+// number of bytes written. If src contains a byte outside the alphabet other
+// than '\r' or '\n', Decode returns the number of bytes written and a
+// CorruptInputError. If dst is too short, it returns the number of bytes
+// written and ErrShortDst. A dst of DecodedLen(len(src)) bytes is always long
+// enough.
+func (enc *RadixEncoding) Decode(dst, src []byte) (int, error) {
+	var queue, numBits uint32
+	v := -1
+	n := 0
+	for i, c := range src {
+		d := enc.decodeMap[c]
+		if d == 0xfe {
+			continue
+		}
+		if d == 0xff {
+			return n, newCorruptInputError(src, i, enc.name)
+		}
+		if v == -1 {
+			v = int(d)
+			continue
+		}
+
+		x := uint32(v) + uint32(d)*enc.radix
+		v = -1
+		queue |= x << numBits
+		if x&enc.mask < enc.threshold {
+			numBits += enc.bits + 1
+		} else {
+			numBits += enc.bits
+		}
+		for numBits > 7 {
+			if n >= len(dst) {
+				return n, ErrShortDst
+			}
+			dst[n] = byte(queue)
+			n++
+			queue >>= 8
+			numBits -= 8
+		}
+	}
+
+	if v != -1 {
+		if n >= len(dst) {
+			return n, ErrShortDst
+		}
+		dst[n] = byte(queue | uint32(v)<<numBits)
+		n++
+	}
+	return n, nil
+}
+
+// DecodeString returns the bytes represented by the string s, which was
+// encoded with enc.
+func (enc *RadixEncoding) DecodeString(s string) ([]byte, error) {
+	dbuf := make([]byte, enc.DecodedLen(len(s)))
+	n, err := enc.Decode(dbuf, stringBytes(s))
+	return dbuf[:n], err
+}
+
+// DecodedLen returns an upper bound on the length in bytes of the data that n
+// bytes of encoded data decode to.
+func (enc *RadixEncoding) DecodedLen(n int) int {
+	// Each pair carries at most enc.bits+1 bits, and a final lone symbol
+	// completes at most one byte.
+	return n/2*int(enc.bits+1)/8 + n%2
+}
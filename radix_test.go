@@ -0,0 +1,113 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestNewEncodingRadix(t *testing.T) {
+	cases := []struct {
+		alphabet  string
+		bits      uint32
+		threshold uint32
+		ok        bool
+	}{
+		{encodeStd, 13, 89, true},
+		{printable[:85], 12, 3129, true},
+		{printable[:94], 13, 644, true},
+		{printable[:84], 0, 0, false},
+		{printable + "\x00", 0, 0, false},
+		{encodeStd[:90] + "A", 0, 0, false},
+		{encodeStd[:90] + "\n", 0, 0, false},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			enc, err := NewEncodingRadix(tc.alphabet)
+			if !tc.ok {
+				if err == nil {
+					t.Errorf("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Got error: %v", err)
+			}
+			if enc.bits != tc.bits || enc.threshold != tc.threshold {
+				t.Errorf("Expected %d bits and threshold %d, got %d and %d", tc.bits, tc.threshold, enc.bits, enc.threshold)
+			}
+		})
+	}
+}
+
+func TestRadixEncodingMatchesStd(t *testing.T) {
+	enc, err := NewEncodingRadix(encodeStd)
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+	for n := 0; n < 300; n++ {
+		data := benchmarkData(n)
+		want := StdEncoding.EncodeToString(data)
+		if got := enc.EncodeToString(data); got != want {
+			t.Fatalf("n=%d: expected %q, got %q", n, want, got)
+		}
+	}
+}
+
+func TestRadixEncodingRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for r := minRadix; r <= maxRadix; r++ {
+		enc, err := NewEncodingRadix(printable[:r])
+		if err != nil {
+			t.Fatalf("radix %d: got error: %v", r, err)
+		}
+		for n := 0; n < 64; n++ {
+			data := make([]byte, n)
+			rng.Read(data)
+			s := enc.EncodeToString(data)
+			if len(s) > enc.EncodedLen(n) {
+				t.Fatalf("radix %d: encoded %d bytes to %d, more than EncodedLen %d", r, n, len(s), enc.EncodedLen(n))
+			}
+			if enc.DecodedLen(len(s)) < n {
+				t.Fatalf("radix %d: DecodedLen(%d) = %d, less than %d", r, len(s), enc.DecodedLen(len(s)), n)
+			}
+			decoded, err := enc.DecodeString(s)
+			if err != nil {
+				t.Fatalf("radix %d: got decoding error: %v", r, err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Fatalf("radix %d: expected %x, got %x", r, data, decoded)
+			}
+		}
+	}
+}
+
+func TestRadixEncodingErrors(t *testing.T) {
+	enc, err := NewEncodingRadix(strings.Replace(encodeStd, "\"", "", 1)[:85])
+	if err != nil {
+		t.Fatalf("Got error: %v", err)
+	}
+
+	if _, err := enc.DecodeString("ab\r\ncd"); err != nil {
+		t.Errorf("Expected line breaks to be ignored, got %v", err)
+	}
+
+	_, err = enc.DecodeString("abc~d")
+	var e CorruptInputError
+	if !errors.As(err, &e) {
+		t.Fatalf("Expected CorruptInputError, got %v", err)
+	}
+	if e.Offset != 3 || e.Encoding != "base91/radix=85" {
+		t.Errorf("Expected offset 3 in base91/radix=85, got %d in %s", e.Offset, e.Encoding)
+	}
+
+	data := benchmarkData(100)
+	dst := make([]byte, 10)
+	if n, err := enc.Encode(dst, data); err != ErrShortDst || n > len(dst) {
+		t.Errorf("Expected ErrShortDst, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,34 @@
+package base91
+
+import (
+	"bufio"
+	"io"
+)
+
+// WriteRecord writes data to w as a record: a single line holding the length of
+// data in decimal, a space, and the base91 encoding of data, without wrapping.
+// This is the same as a frame line in the framed container format (see
+// frame.go) without a checksum. Since each record is a line and states its own
+// length, many messages can be sent over one stream and read back with
+// ReadRecord, and a truncated or damaged record is detected rather than
+// silently merged with the next one.
+func WriteRecord(w io.Writer, enc *Encoding, data []byte) error {
+	_, err := w.Write(appendFrame(nil, enc, data, false))
+	return err
+}
+
+// ReadRecord reads a record written by WriteRecord from r and returns its
+// data. It returns io.EOF if there are no more records, and ErrBadFrame if the
+// data does not have the length stated in the record. If enc has a maximum
+// decoded length, a record that states a longer length is rejected with
+// ErrTooLarge before it is decoded.
+//
+// r is a bufio.Reader so that the data that follows the record in the stream
+// is not lost when ReadRecord reads ahead.
+func ReadRecord(r *bufio.Reader, enc *Encoding) ([]byte, error) {
+	line, _, err := readFrameLine(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFrame(enc, line, false)
+}
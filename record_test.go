@@ -0,0 +1,72 @@
+package base91
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteRecord(t *testing.T) {
+	var buf bytes.Buffer
+	for _, rec := range []string{"foo", "", "foobar"} {
+		if err := WriteRecord(&buf, StdEncoding, []byte(rec)); err != nil {
+			t.Fatalf("Got writing error: %v", err)
+		}
+	}
+
+	want := "3 dr.J\n0 \n6 dr/2s)uC\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestReadRecord(t *testing.T) {
+	data := benchmarkData(1000)
+	var buf bytes.Buffer
+	for _, n := range []int{0, 1, 10, 1000} {
+		WriteRecord(&buf, StdEncoding, data[:n])
+	}
+
+	r := bufio.NewReader(&buf)
+	for i, n := range []int{0, 1, 10, 1000} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			got, err := ReadRecord(r, StdEncoding)
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(got, data[:n]) {
+				t.Errorf("Expected %x, got %x", data[:n], got)
+			}
+		})
+	}
+	if _, err := ReadRecord(r, StdEncoding); err != io.EOF {
+		t.Errorf("Expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestReadRecordErrors(t *testing.T) {
+	limited := NewEncodingWithOptions(StdEncoding.Alphabet(), MaxDecodedLen(2))
+	cases := []struct {
+		enc *Encoding
+		in  string
+		err error
+	}{
+		{StdEncoding, "3 dr.J", io.ErrUnexpectedEOF},
+		{StdEncoding, "dr.J\n", ErrBadFrame},
+		{StdEncoding, "4 dr.J\n", ErrBadFrame},
+		{StdEncoding, "2 dr.J\n", ErrBadFrame},
+		{limited, "3 dr.J\n", ErrTooLarge},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := ReadRecord(bufio.NewReader(strings.NewReader(tc.in)), tc.enc)
+			if err != tc.err {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package base91
+
+import "crypto/subtle"
+
+// DecodeConstantTime is like Decode but is meant for secrets such as API keys
+// and tokens. Its running time depends only on len(src) and on the length of
+// the decoded data, not on whether or where src contains invalid bytes: it
+// never stops early, and it looks up each input byte by scanning the whole
+// decode map rather than indexing it, so that memory access patterns do not
+// depend on the input either. If src is invalid it returns 0 and a
+// CorruptInputError for the first problem, and dst is left unchanged.
+//
+// dst must be at least DecodedLen(len(src)) bytes long, or ErrShortDst is
+// returned. DecodeConstantTime is much slower than Decode, so it should only be
+// used for short, sensitive inputs.
+//
+// So as not to leak the secret, a CorruptInputError from DecodeConstantTime
+// holds no input bytes: its Byte is zero and its Context is empty.
+//
+// The scratch buffer that DecodeConstantTime decodes into is zeroed before it
+// returns; see DecodeSecret.
+func (enc *Encoding) DecodeConstantTime(dst, src []byte) (int, error) {
+	if len(dst) < enc.DecodedLen(len(src)) {
+		return 0, ErrShortDst
+	}
+
+	out := make([]byte, enc.DecodedLen(len(src))+2)
+	defer wipe(out)
+
+	n, err := enc.decodeConstantTime(out, src)
+	if err != nil {
+		return 0, err
+	}
+	return copy(dst, out[:n]), nil
+}
+
+// DecodeSecret returns the bytes represented by src, decoding them as
+// DecodeConstantTime does. Every buffer that DecodeSecret uses to hold decoded
+// data, other than the returned slice, is zeroed before it returns, and the
+// returned slice has no spare capacity that could hold a stray copy, and a
+// CorruptInputError holds no input bytes. Callers should zero the returned
+// slice once they are done with it.
+//
+// Go does not allow control over copies of values held in registers or on the
+// stack, so this is a best effort: it guarantees only that no heap memory
+// allocated by DecodeSecret retains the secret.
+func (enc *Encoding) DecodeSecret(src []byte) ([]byte, error) {
+	out := make([]byte, enc.DecodedLen(len(src))+2)
+	defer wipe(out)
+
+	n, err := enc.decodeConstantTime(out, src)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, n)
+	copy(result, out)
+	return result, nil
+}
+
+// decodeConstantTime implements DecodeConstantTime, decoding src into out,
+// which must be at least DecodedLen(len(src))+2 bytes long, and returning the
+// number of bytes decoded.
+func (enc *Encoding) decodeConstantTime(out, src []byte) (int, error) {
+	var queue, numBits, v, half, start, lastStart, lastV, lastNumBits uint32
+	var bad, first uint32
+	layout := newCTLayout(enc)
+	n := uint32(0)
+	for i := 0; i < len(src); i++ {
+		d := enc.lookupConstantTime(src[i])
+		layout.next(src[i], d, uint32(i))
+
+		isSym := uint32(subtle.ConstantTimeLessOrEq(int(d), 90))
+		isInvalid := uint32(subtle.ConstantTimeByteEq(d, 0xff))
+		first = ctSelect(isInvalid&^bad, uint32(i), first)
+		bad |= isInvalid
+
+		// A symbol either starts a group or completes a pair.
+		startPair := isSym &^ half
+		completePair := isSym & half
+		v = ctSelect(startPair, uint32(d), v)
+		start = ctSelect(startPair, uint32(i), start)
+		half ^= isSym
+
+		pv := v + uint32(d)*91
+		bits := 13 + uint32(subtle.ConstantTimeLessOrEq(int(pv&8191), 88))
+		lastStart = ctSelect(completePair, start, lastStart)
+		lastV = ctSelect(completePair, pv, lastV)
+		lastNumBits = ctSelect(completePair, numBits, lastNumBits)
+		queue |= (pv << numBits) & -completePair
+		numBits += bits & -completePair
+
+		// Output is written two bytes at a time, whether or not they are
+		// complete, so that the number of writes does not depend on the data.
+		// out has room for the overrun.
+		out[n] = byte(queue)
+		out[n+1] = byte(queue >> 8)
+		emit := numBits >> 3
+		n += emit
+		queue >>= 8 * emit
+		numBits -= 8 * emit
+	}
+
+	// A trailing lone symbol supplies the rest of one final byte.
+	out[n] = byte(queue | v<<numBits)
+	n += half
+
+	// Check the final group for strict decoding, without branching on which
+	// check applies or on its result.
+	lastBits := 13 + uint32(subtle.ConstantTimeLessOrEq(int(lastV&8191), 88))
+	symbolOK := ctFinalSymbol(v, numBits)
+	pairOK := ctSelect(uint32(subtle.ConstantTimeEq(int32(n), 0)), 1, ctFinalPair(lastV, lastBits, lastNumBits))
+	finalBad := ctSelect(half, 1^symbolOK, 1^pairOK)
+	finalStart := ctSelect(half, start, lastStart)
+
+	// Clear the state that holds decoded bits.
+	queue, v, lastV = 0, 0, 0
+
+	if enc.checksLayout() {
+		if layout.bad != 0 {
+			return 0, enc.secretInputError(int(layout.first))
+		}
+		if layout.end() == 0 {
+			return 0, enc.secretInputError(len(src) - 1)
+		}
+	}
+	if bad != 0 {
+		return 0, enc.secretInputError(int(first))
+	}
+	if enc.strict && finalBad != 0 {
+		return 0, enc.secretInputError(int(finalStart))
+	}
+	return int(n), nil
+}
+
+// secretInputError returns a CorruptInputError for the data at offset i of a
+// secret, which unlike corruptInputError holds none of the input.
+func (enc *Encoding) secretInputError(i int) error {
+	return CorruptInputError{Offset: int64(i), Encoding: enc.String()}
+}
+
+// ctFinalSymbol is like canonicalFinalSymbol but takes time independent of its
+// arguments, and returns 1 for true and 0 for false.
+func ctFinalSymbol(v, r uint32) uint32 {
+	rPos := 1 ^ uint32(subtle.ConstantTimeEq(int32(r), 0))
+	fits := uint32(subtle.ConstantTimeLessOrEq(int(v)+1, 1<<(8-r)))
+	return rPos & fits
+}
+
+// ctFinalPair is like canonicalFinalPair but takes time independent of its
+// arguments, and returns 1 for true and 0 for false.
+func ctFinalPair(v, numBits, r uint32) uint32 {
+	t := (r+numBits)/8*8 - r
+	padded := 1 ^ uint32(subtle.ConstantTimeLessOrEq(int(numBits), int(t)))
+	dirty := 1 ^ uint32(subtle.ConstantTimeEq(int32(v>>t), 0))
+	long := 1 ^ uint32(subtle.ConstantTimeLessOrEq(int(t), 7))
+	big := 1 ^ uint32(subtle.ConstantTimeLessOrEq(int(v), 90))
+	return (1 ^ padded&dirty) & (long | big)
+}
+
+// A ctLayout is a layoutChecker for DecodeConstantTime, which tracks the
+// layout of the input without branching on it. Its flags are 0 or 1.
+type ctLayout struct {
+	wrap, sep, nl, sepNL uint32 // From enc, with nl set if it adds a trailing newline.
+	col, broke, ended    uint32
+	bad, first           uint32 // Whether a byte was out of place, and the first.
+}
+
+func newCTLayout(enc *Encoding) ctLayout {
+	var nl, sepNL uint32
+	if enc.trailingNewline {
+		nl = 1
+	}
+	if enc.sep == '\n' {
+		sepNL = 1
+	}
+	return ctLayout{wrap: uint32(enc.wrap), sep: uint32(enc.sep), nl: nl, sepNL: sepNL}
+}
+
+// next records c, the input byte at offset i, with d its value in the decode
+// map.
+func (l *ctLayout) next(c, d byte, i uint32) {
+	hasWrap := 1 ^ uint32(subtle.ConstantTimeEq(int32(l.wrap), 0))
+	full := hasWrap & uint32(subtle.ConstantTimeEq(int32(l.col), int32(l.wrap)))
+	isSym := uint32(subtle.ConstantTimeLessOrEq(int(d), 90))
+	isSep := uint32(subtle.ConstantTimeEq(int32(c), int32(l.sep)))
+	isNL := uint32(subtle.ConstantTimeByteEq(c, '\n'))
+	colZero := uint32(subtle.ConstantTimeEq(int32(l.col), 0))
+
+	okSym := isSym &^ full
+	okSep := (1 ^ isSym) & isSep & full
+	okNL := (1 ^ isSym) &^ okSep & isNL & l.nl &^ colZero
+	ok := (okSym | okSep | okNL) &^ l.ended
+
+	l.first = ctSelect((1^ok)&^l.bad, i, l.first)
+	l.bad |= 1 ^ ok
+	l.col = ctSelect(okSym, l.col+1, l.col&-(1^okSep))
+	l.broke = ctSelect(okSym|okSep, okSep, l.broke)
+	l.ended |= okNL
+}
+
+// end returns 1 if the input may end after the bytes passed to next, and 0
+// otherwise.
+func (l *ctLayout) end() uint32 {
+	colZero := uint32(subtle.ConstantTimeEq(int32(l.col), 0))
+	notBroke := ctSelect(l.nl, l.ended|colZero, 1)
+	return ctSelect(l.broke, l.nl&l.sepNL, notBroke)
+}
+
+// wipe sets every byte of b to zero.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// lookupConstantTime returns enc.decodeMap[c] by scanning the entire map, so
+// that the memory accessed does not depend on c.
+func (enc *Encoding) lookupConstantTime(c byte) byte {
+	var d byte
+	for i := 0; i < len(enc.decodeMap); i++ {
+		eq := byte(subtle.ConstantTimeByteEq(byte(i), c))
+		d |= enc.decodeMap[i] & -eq
+	}
+	return d
+}
+
+// ctSelect returns x if b is 1 and y if b is 0.
+func ctSelect(b, x, y uint32) uint32 {
+	return x&-b | y&^-b
+}
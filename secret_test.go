@@ -0,0 +1,152 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDecodeConstantTime(t *testing.T) {
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			for _, enc := range []*Encoding{StdEncoding, StdEncoding.WithWrap(3)} {
+				src := []byte(enc.EncodeToString([]byte(p.decoded)))
+				dst := make([]byte, enc.DecodedLen(len(src)))
+
+				n, err := enc.DecodeConstantTime(dst, src)
+				if err != nil {
+					t.Errorf("Got decoding error: %v", err)
+				} else if got := dst[:n]; !bytes.Equal(got, []byte(p.decoded)) {
+					t.Errorf("Expected %v, got %v", []byte(p.decoded), got)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeConstantTimeMatchesDecode(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	wrapped := NewEncodingWithOptions(encodeStd, Strict(), Wrap(3), TrailingNewline())
+	r := rand.New(rand.NewSource(91))
+	alphabet := encodeStd + "\n -"
+
+	for i := 0; i < 2000; i++ {
+		src := make([]byte, r.Intn(12))
+		for j := range src {
+			src[j] = alphabet[r.Intn(len(alphabet))]
+		}
+
+		for _, enc := range []*Encoding{StdEncoding, strict, wrapped} {
+			want := make([]byte, enc.DecodedLen(len(src)))
+			wantN, wantErr := enc.Decode(want, src)
+
+			got := make([]byte, enc.DecodedLen(len(src)))
+			gotN, gotErr := enc.DecodeConstantTime(got, src)
+
+			if wantErr != nil {
+				var we, ge CorruptInputError
+				if !errors.As(wantErr, &we) || !errors.As(gotErr, &ge) || we.Offset != ge.Offset {
+					t.Errorf("%v: %q: expected error %v, got %v", enc, src, wantErr, gotErr)
+				}
+				continue
+			}
+			if gotErr != nil || !bytes.Equal(got[:gotN], want[:wantN]) {
+				t.Errorf("%v: %q: expected %x, got %x (error: %v)", enc, src, want[:wantN], got[:gotN], gotErr)
+			}
+		}
+	}
+}
+
+func TestDecodeConstantTimeErrorHidesInput(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict(), Wrap(4), TrailingNewline())
+	cases := []struct {
+		input  string
+		offset int64
+	}{
+		{"dr/2\n-)uC\n", 5},   // Invalid byte.
+		{"dr/2\n\ns)uC\n", 5}, // Line break out of place.
+		{"dr/2\ns)uC", 8},     // No trailing newline.
+		{"dr.:\n", 2},         // Non-zero padding bits.
+		{"dr\"\n", 2},         // Lone symbol with bits beyond the final byte.
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			dst := make([]byte, strict.DecodedLen(len(tc.input)))
+			_, err := strict.DecodeConstantTime(dst, []byte(tc.input))
+			want := CorruptInputError{Offset: tc.offset, Encoding: strict.String()}
+			if err != want {
+				t.Fatalf("Expected %#v, got %#v", want, err)
+			}
+			msg := "illegal base91 data at input byte " + fmt.Sprint(tc.offset) + " in " + strict.String()
+			if got := err.Error(); got != msg {
+				t.Errorf("Expected %q, got %q", msg, got)
+			}
+		})
+	}
+}
+
+func TestCTFinalGroup(t *testing.T) {
+	b := map[bool]uint32{false: 0, true: 1}
+	for r := uint32(0); r < 8; r++ {
+		for v := uint32(0); v < 91*91; v++ {
+			if v < 91 {
+				if got, want := ctFinalSymbol(v, r), b[canonicalFinalSymbol(v, r)]; got != want {
+					t.Errorf("ctFinalSymbol(%d, %d): expected %d, got %d", v, r, want, got)
+				}
+			}
+			bits := groupBits(v)
+			if got, want := ctFinalPair(v, bits, r), b[canonicalFinalPair(v, bits, r)]; got != want {
+				t.Errorf("ctFinalPair(%d, %d, %d): expected %d, got %d", v, bits, r, want, got)
+			}
+		}
+	}
+}
+
+func TestDecodeConstantTimeShortDst(t *testing.T) {
+	dst := make([]byte, 5)
+	if _, err := StdEncoding.DecodeConstantTime(dst, []byte("dr/2s)uC")); err != ErrShortDst {
+		t.Errorf("Expected ErrShortDst, got %v", err)
+	}
+}
+
+func TestDecodeSecret(t *testing.T) {
+	for i, p := range pairs {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			got, err := StdEncoding.DecodeSecret([]byte(p.encoded))
+			if err != nil {
+				t.Errorf("Got decoding error: %v", err)
+			} else if !bytes.Equal(got, []byte(p.decoded)) {
+				t.Errorf("Expected %v, got %v", []byte(p.decoded), got)
+			}
+			if cap(got) != len(got) {
+				t.Errorf("Expected no spare capacity, got len %d, cap %d", len(got), cap(got))
+			}
+		})
+	}
+
+	// None of the bytes of src appear in the message for a secret.
+	src := []byte("QZ!#-%&XW")
+	_, err := StdEncoding.DecodeSecret(src)
+	if !errors.Is(err, ErrCorruptInput) {
+		t.Fatalf("Expected ErrCorruptInput, got %v", err)
+	}
+	e := err.(CorruptInputError)
+	if e.Offset != 4 || e.Byte != 0 || e.Context() != "" {
+		t.Errorf("Expected offset 4 and no input bytes, got %#v", e)
+	}
+	if msg := e.Error(); strings.ContainsAny(msg, string(src)) {
+		t.Errorf("Expected no input bytes in %q", msg)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	b := []byte("secret")
+	wipe(b)
+	if !bytes.Equal(b, make([]byte, 6)) {
+		t.Errorf("Expected zeroes, got %v", b)
+	}
+}
@@ -0,0 +1,41 @@
+package base91
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// Value implements driver.Valuer, so that a Bytes can be stored in a text
+// column as its base91 encoding in the standard alphabet. A nil Bytes is stored
+// as NULL. This lets binary data pass through databases and replication
+// channels that handle only text.
+func (b Bytes) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return StdEncoding.EncodeToString(b), nil
+}
+
+// Scan implements sql.Scanner, decoding a base91 value read from a text column
+// into *b. NULL is read as nil.
+func (b *Bytes) Scan(src interface{}) error {
+	var decoded []byte
+	var err error
+	switch src := src.(type) {
+	case nil:
+		*b = nil
+		return nil
+	case string:
+		decoded, err = StdEncoding.DecodeString(src)
+	case []byte:
+		// The driver may reuse src, but decoding copies it.
+		decoded, err = StdEncoding.decodeToNew(src, false)
+	default:
+		return errors.New("cannot scan non-text value into base91.Bytes")
+	}
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
@@ -0,0 +1,63 @@
+package base91
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	_ driver.Valuer = Bytes(nil)
+	_ sql.Scanner   = (*Bytes)(nil)
+)
+
+func TestBytesSQL(t *testing.T) {
+	cases := []struct {
+		b     Bytes
+		value driver.Value
+	}{
+		{nil, nil},
+		{Bytes{}, ""},
+		{Bytes("foobar"), "dr/2s)uC"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			v, err := tc.b.Value()
+			if err != nil {
+				t.Fatalf("Got error: %v", err)
+			}
+			if v != tc.value {
+				t.Errorf("Expected %#v, got %#v", tc.value, v)
+			}
+
+			// Drivers may return text as either a string or a []byte.
+			srcs := []interface{}{v}
+			if s, ok := v.(string); ok {
+				srcs = append(srcs, []byte(s))
+			}
+			for _, src := range srcs {
+				b := Bytes("old")
+				if err := b.Scan(src); err != nil {
+					t.Fatalf("Got scanning error: %v", err)
+				}
+				if (b == nil) != (tc.b == nil) || !bytes.Equal(b, tc.b) {
+					t.Errorf("Expected %#v, got %#v", tc.b, b)
+				}
+			}
+		})
+	}
+}
+
+func TestBytesScanInvalid(t *testing.T) {
+	var b Bytes
+	if err := b.Scan("ab c"); !errors.Is(err, ErrCorruptInput) {
+		t.Errorf("Expected ErrCorruptInput, got %v", err)
+	}
+	if err := b.Scan(int64(12)); err == nil {
+		t.Errorf("Expected error for an integer, got none")
+	}
+}
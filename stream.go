@@ -0,0 +1,332 @@
+package base91
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// streamChunkLen is the number of input bytes that the stream encoder and
+// decoder process at a time.
+const streamChunkLen = 1024
+
+// errClosed is returned by writes to a stream encoder after Close.
+var errClosed = errors.New("write to closed base91 encoder")
+
+type streamEncoder struct {
+	e      encoder
+	w      io.Writer
+	header []byte // Stream header not yet written, if any.
+	buf    [2 * streamChunkLen]byte
+	err    error
+}
+
+// NewEncoder returns a new base91 stream encoder. Data written to the returned
+// writer is encoded using enc and then written to w, wrapped and ended as
+// configured for enc, so that the output is the same as that of Encode. Since
+// base91 encodes bits rather than whole bytes, the end of the encoding is only
+// written when the writer is closed, so callers must Close it when done.
+// Closing it does not close w.
+func NewEncoder(enc *Encoding, w io.Writer) io.WriteCloser {
+	return &streamEncoder{e: encoder{enc: enc}, w: w}
+}
+
+// NewEncoderWithHeader is like NewEncoder but writes a stream header before the
+// encoded data, so that the output is self-describing. The output must be
+// decoded with a decoder returned by NewDecoderWithHeader.
+func NewEncoderWithHeader(enc *Encoding, w io.Writer) io.WriteCloser {
+	return &streamEncoder{e: encoder{enc: enc}, w: w, header: appendStreamHeader(nil, enc)}
+}
+
+func (s *streamEncoder) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.err = s.writeHeader(); s.err != nil {
+		return 0, s.err
+	}
+
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > streamChunkLen {
+			chunk = chunk[:streamChunkLen]
+		}
+		k := s.e.encodeBlock(s.buf[:], chunk)
+		if s.err = s.e.writeLines(s.w, s.buf[:k]); s.err != nil {
+			return n, s.err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// Close writes the symbols for any bits still queued, and the trailing newline
+// if enc calls for one.
+func (s *streamEncoder) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.err = s.writeHeader(); s.err != nil {
+		return s.err
+	}
+
+	k := s.e.flush(s.buf[:])
+	if s.err = s.e.writeLines(s.w, s.buf[:k]); s.err == nil {
+		s.err = s.e.finish(s.w)
+	}
+	if s.err != nil {
+		return s.err
+	}
+	s.err = errClosed
+	return nil
+}
+
+// writeHeader writes the stream header if there is one that has not yet been
+// written.
+func (s *streamEncoder) writeHeader() error {
+	if s.header == nil {
+		return nil
+	}
+	if _, err := s.w.Write(s.header); err != nil {
+		return err
+	}
+	s.header = nil
+	return nil
+}
+
+type streamDecoder struct {
+	enc    *Encoding
+	r      io.Reader
+	header bool // Whether a stream header has yet to be read.
+	in     [streamChunkLen]byte
+	outBuf [streamChunkLen + 2]byte
+	out    []byte // Decoded data not yet returned by Read.
+	err    error
+
+	queue   uint32
+	numBits uint32
+	v       int   // Value of a pending first symbol, or -1 if there is none.
+	off     int64 // Offset in the stream of in[0].
+	total   int64 // Number of bytes decoded so far.
+
+	// The offset and first byte of the pending symbol, and of the most recent
+	// pair with its value and preceding leftover bit count, which strict
+	// decoding needs in order to check the end of the stream.
+	start, lastStart         int64
+	startByte, lastStartByte byte
+	lastV, lastNumBits       uint32
+
+	// The layout of the input so far, and its last byte, which strict decoding
+	// checks as Decode does.
+	layout   layoutChecker
+	lastByte byte
+}
+
+// NewDecoder returns a new base91 stream decoder that reads data encoded with
+// enc from r. It accepts the same input as Decode, and reports invalid input
+// with a CorruptInputError whose offset is counted from the start of the
+// stream.
+func NewDecoder(enc *Encoding, r io.Reader) io.Reader {
+	return &streamDecoder{enc: enc, r: r, v: -1, layout: layoutChecker{enc: enc}}
+}
+
+// NewDecoderWithHeader is like NewDecoder but first reads and checks the stream
+// header written by an encoder returned by NewEncoderWithHeader. If the header
+// is malformed or from a later version of the format, reads return
+// ErrBadHeader, and if the data was encoded with a different alphabet than
+// that of enc, they return ErrAlphabetMismatch.
+func NewDecoderWithHeader(enc *Encoding, r io.Reader) io.Reader {
+	return &streamDecoder{enc: enc, r: r, v: -1, header: true, layout: layoutChecker{enc: enc}}
+}
+
+func (d *streamDecoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.fill()
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// fill reads and decodes the next chunk of input, setting d.out to the result
+// and d.err to any error that ends the stream.
+func (d *streamDecoder) fill() {
+	if d.header {
+		d.err = d.readHeader()
+		d.header = false
+		if d.err != nil {
+			return
+		}
+	}
+
+	k, err := d.r.Read(d.in[:])
+	if k > 0 {
+		d.lastByte = d.in[k-1]
+	}
+	n, derr := d.decodeChunk(d.in[:k])
+	d.off += int64(k)
+	d.total += int64(n)
+	if derr == nil && err == io.EOF {
+		var m int
+		m, derr = d.finish(d.outBuf[n:])
+		n += m
+	}
+	d.out = d.outBuf[:n]
+	if derr != nil {
+		d.err = derr
+	} else if err != nil {
+		d.err = err
+	}
+}
+
+// decodeChunk decodes src, the next bytes of the stream, into d.outBuf, and
+// returns the number of bytes written. A symbol that does not yet make up a
+// pair remains pending for the next call or for finish.
+func (d *streamDecoder) decodeChunk(src []byte) (int, error) {
+	queue, numBits := d.queue, d.numBits
+	layout := d.enc.checksLayout()
+	n := 0
+	for i, c := range src {
+		x := d.enc.decodeMap[c]
+		if x == 0xff || layout && !d.layout.next(c) {
+			d.queue, d.numBits = queue, numBits
+			e := d.enc.corruptInputError(src, i).(CorruptInputError)
+			e.Offset += d.off
+			return n, e
+		}
+		if x == 0xfe {
+			continue
+		}
+
+		if d.v == -1 {
+			d.v = int(x)
+			d.start, d.startByte = d.off+int64(i), c
+			continue
+		}
+		v := uint32(d.v + int(x)*91)
+		d.v = -1
+
+		d.lastStart, d.lastStartByte = d.start, d.startByte
+		d.lastV, d.lastNumBits = v, numBits
+		queue |= v << numBits
+		numBits += groupBits(v)
+		for numBits > 7 {
+			d.outBuf[n] = byte(queue)
+			n++
+			queue >>= 8
+			numBits -= 8
+		}
+	}
+
+	d.queue, d.numBits = queue, numBits
+	return n, nil
+}
+
+// finish decodes any pending symbol at the end of the stream into dst, and
+// returns the number of bytes written. If enc is strict, it also checks that
+// the stream ends as the encoder would have ended it.
+func (d *streamDecoder) finish(dst []byte) (int, error) {
+	if d.enc.checksLayout() && !d.layout.end() {
+		return 0, d.corruptInputError(d.off-1, d.lastByte)
+	}
+	if d.v != -1 {
+		if d.enc.strict && !canonicalFinalSymbol(uint32(d.v), d.numBits) {
+			return 0, d.corruptInputError(d.start, d.startByte)
+		}
+		dst[0] = byte(d.queue | uint32(d.v)<<d.numBits)
+		return 1, nil
+	}
+	if d.enc.strict && d.total > 0 && !canonicalFinalPair(d.lastV, groupBits(d.lastV), d.lastNumBits) {
+		return 0, d.corruptInputError(d.lastStart, d.lastStartByte)
+	}
+	return 0, nil
+}
+
+// corruptInputError returns a CorruptInputError for the byte c at offset off in
+// the stream, for a problem found once the input around it is gone.
+func (d *streamDecoder) corruptInputError(off int64, c byte) error {
+	e := CorruptInputError{Offset: off, Byte: c, Encoding: d.enc.String()}
+	e.context[0], e.contextN = c, 1
+	return e
+}
+
+// The stream header is a single line, such as
+//
+//	base91/1 0 std
+//
+// that names the format and its version, gives flags in hexadecimal, and
+// identifies the alphabet as "std" for the standard alphabet or as
+// "custom-" and the FNV-1a hash of the alphabet in 8 hexadecimal digits. No
+// flags are defined in version 1; they are reserved for future features, such
+// as framing and checksums, that change how the data that follows must be
+// read. A decoder rejects flags that it does not know, so that it never
+// misreads data written by a later version.
+
+const streamMagic = "base91/"
+
+// streamVersion is the version of the stream header format written by
+// NewEncoderWithHeader.
+const streamVersion = 1
+
+// streamFlags is the set of flags that this version of the package understands.
+const streamFlags = 0
+
+// ErrBadHeader is returned by a decoder returned by NewDecoderWithHeader when
+// the stream header is missing or malformed, or is from a version of the
+// format, or has flags, that this package does not support.
+var ErrBadHeader = errors.New("malformed or unsupported base91 stream header")
+
+// ErrAlphabetMismatch is returned by a decoder returned by
+// NewDecoderWithHeader when the stream header shows that the data was encoded
+// with a different alphabet than that of the decoder's Encoding.
+var ErrAlphabetMismatch = errors.New("base91 stream was encoded with a different alphabet")
+
+// appendStreamHeader appends the stream header, including its line break, for
+// data encoded with enc to b.
+func appendStreamHeader(b []byte, enc *Encoding) []byte {
+	b = append(b, streamMagic...)
+	b = strconv.AppendInt(b, streamVersion, 10)
+	b = append(b, ' ')
+	b = strconv.AppendUint(b, streamFlags, 16)
+	b = append(b, ' ')
+	b = append(b, enc.alphabetID()...)
+	return append(b, '\n')
+}
+
+// readHeader reads and checks the stream header. The rest of the stream is
+// then read through the bufio.Reader that it reads the header with.
+func (d *streamDecoder) readHeader() error {
+	br := bufio.NewReader(d.r)
+	d.r = br
+	line, err := br.ReadSlice('\n')
+	if err == io.EOF || err == bufio.ErrBufferFull {
+		return ErrBadHeader
+	}
+	if err != nil {
+		return err
+	}
+	d.off = int64(len(line))
+
+	fields := bytes.Split(trimFrameLine(line), []byte{' '})
+	if len(fields) != 3 || !bytes.HasPrefix(fields[0], []byte(streamMagic)) {
+		return ErrBadHeader
+	}
+	version, err := strconv.Atoi(string(fields[0][len(streamMagic):]))
+	if err != nil || version != streamVersion {
+		return ErrBadHeader
+	}
+	flags, err := strconv.ParseUint(string(fields[1]), 16, 32)
+	if err != nil || flags&^streamFlags != 0 {
+		return ErrBadHeader
+	}
+	if string(fields[2]) != d.enc.alphabetID() {
+		return ErrAlphabetMismatch
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package base91
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEncoder(t *testing.T) {
+	data := benchmarkData(5000)
+	encodings := []*Encoding{
+		StdEncoding,
+		NewEncodingWithOptions(encodeStd, Wrap(76)),
+		NewEncodingWithOptions(encodeStd, Wrap(10), TrailingNewline()),
+	}
+	cases := []struct {
+		n         int
+		chunkSize int
+	}{
+		{0, 1},
+		{1, 1},
+		{2, 1},
+		{100, 1},
+		{100, 7},
+		{5000, 1000},
+		{5000, 5000},
+	}
+
+	for i, enc := range encodings {
+		for j, tc := range cases {
+			t.Run(fmt.Sprintf("case_%d_%d", i, j), func(t *testing.T) {
+				src := data[:tc.n]
+				var buf bytes.Buffer
+				w := NewEncoder(enc, &buf)
+				for start := 0; start < len(src); start += tc.chunkSize {
+					end := start + tc.chunkSize
+					if end > len(src) {
+						end = len(src)
+					}
+					if _, err := w.Write(src[start:end]); err != nil {
+						t.Fatalf("Got writing error: %v", err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					t.Fatalf("Got closing error: %v", err)
+				}
+
+				if want := enc.EncodeToString(src); buf.String() != want {
+					t.Errorf("Expected %q, got %q", want, buf.String())
+				}
+			})
+		}
+	}
+}
+
+func TestEncoderClosed(t *testing.T) {
+	w := NewEncoder(StdEncoding, io.Discard)
+	w.Close()
+	if _, err := w.Write([]byte("foo")); err == nil {
+		t.Error("Expected error writing after Close, got nil")
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	data := benchmarkData(5000)
+	cases := []struct {
+		enc    *Encoding
+		n      int
+		reader func(io.Reader) io.Reader
+	}{
+		{StdEncoding, 0, nil},
+		{StdEncoding, 1, nil},
+		{StdEncoding, 2, iotest.OneByteReader},
+		{StdEncoding, 100, iotest.OneByteReader},
+		{StdEncoding, 100, iotest.DataErrReader},
+		{StdEncoding, 5000, nil},
+		{StdEncoding, 5000, iotest.HalfReader},
+		{NewEncodingWithOptions(encodeStd, Wrap(76)), 5000, iotest.HalfReader},
+		{NewEncodingWithOptions(encodeStd, Strict(), Wrap(10), TrailingNewline()), 5000, nil},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			src := data[:tc.n]
+			var r io.Reader = strings.NewReader(tc.enc.EncodeToString(src))
+			if tc.reader != nil {
+				r = tc.reader(r)
+			}
+			decoded, err := io.ReadAll(NewDecoder(tc.enc, r))
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, src) {
+				t.Errorf("Expected %x, got %x", src, decoded)
+			}
+		})
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	strict := NewEncodingWithOptions(encodeStd, Strict())
+	long := strings.Repeat("A", 3000)
+	cases := []struct {
+		enc    *Encoding
+		in     string
+		offset int64
+	}{
+		{StdEncoding, "-", 0},
+		{StdEncoding, "dr.J-", 4},
+		{StdEncoding, long + "-" + long, 3000},
+		{strict, "dr.J\r", 4},
+		// Non-zero padding bits in the final pair and symbol.
+		{strict, "drz~", 2},
+		{strict, "B", 0},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, want := tc.enc.DecodeString(tc.in)
+			_, err := io.ReadAll(NewDecoder(tc.enc, iotest.HalfReader(strings.NewReader(tc.in))))
+			var e CorruptInputError
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected CorruptInputError, got %v", err)
+			}
+			if e.Offset != tc.offset {
+				t.Errorf("Expected offset %d, got %d", tc.offset, e.Offset)
+			}
+			if want.(CorruptInputError).Offset != e.Offset {
+				t.Errorf("Expected offset %d to match Decode, got %d", want.(CorruptInputError).Offset, e.Offset)
+			}
+		})
+	}
+}
+
+func TestStreamHeader(t *testing.T) {
+	custom, err := NewEncodingOmitting("\"'-\\")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := benchmarkData(1000)
+	for i, enc := range []*Encoding{StdEncoding, custom, NewEncodingWithOptions(encodeStd, Wrap(76))} {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewEncoderWithHeader(enc, &buf)
+			w.Write(data)
+			if err := w.Close(); err != nil {
+				t.Fatalf("Got closing error: %v", err)
+			}
+
+			header := "base91/1 0 " + enc.alphabetID() + "\n"
+			if !strings.HasPrefix(buf.String(), header) {
+				t.Errorf("Expected header %q, got %q", header, buf.String())
+			}
+			if rest := buf.String()[len(header):]; rest != enc.EncodeToString(data) {
+				t.Errorf("Expected %q, got %q", enc.EncodeToString(data), rest)
+			}
+
+			decoded, err := io.ReadAll(NewDecoderWithHeader(enc, &buf))
+			if err != nil {
+				t.Fatalf("Got decoding error: %v", err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("Expected %x, got %x", data, decoded)
+			}
+		})
+	}
+}
+
+func TestStreamHeaderErrors(t *testing.T) {
+	cases := []struct {
+		in  string
+		err error
+	}{
+		{"", ErrBadHeader},
+		{"dr.J", ErrBadHeader},
+		{"base91/1 0 std", ErrBadHeader},
+		{"base91/2 0 std\ndr.J", ErrBadHeader},
+		{"base91/x 0 std\ndr.J", ErrBadHeader},
+		{"base91/1 1 std\ndr.J", ErrBadHeader},
+		{"base91/1 0\ndr.J", ErrBadHeader},
+		{"base91-frames/1\n", ErrBadHeader},
+		{"base91/1 0 custom-12345678\ndr.J", ErrAlphabetMismatch},
+		{strings.Repeat("A", 5000), ErrBadHeader},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := io.ReadAll(NewDecoderWithHeader(StdEncoding, strings.NewReader(tc.in)))
+			if err != tc.err {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	// The offsets of errors in the data count the header.
+	_, err := io.ReadAll(NewDecoderWithHeader(StdEncoding, strings.NewReader("base91/1 0 std\ndr-J")))
+	var e CorruptInputError
+	if !errors.As(err, &e) || e.Offset != 17 {
+		t.Errorf("Expected CorruptInputError at offset 17, got %v", err)
+	}
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	src := benchmarkData(64 << 10)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		w := NewEncoder(StdEncoding, io.Discard)
+		w.Write(src)
+		w.Close()
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	src := []byte(StdEncoding.EncodeToString(benchmarkData(64 << 10)))
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		io.Copy(io.Discard, NewDecoder(StdEncoding, bytes.NewReader(src)))
+	}
+}
@@ -0,0 +1,172 @@
+package base91
+
+import "encoding/binary"
+
+// The functions in this file are the word-at-a-time fast paths of the encoder
+// and decoder. They handle the bulk of long inputs, reading and writing 8 bytes
+// at a time, and leave the ends, and anything unusual such as line breaks, to
+// the byte-at-a-time loops that call them. Both produce exactly the same
+// output as those loops.
+
+// encodeWords encodes src to dst 8 bytes at a time, starting from the given
+// bit queue, for as long as at least 8 bytes of src remain and dst has room for
+// a 16-byte window, enough for the up to 5 symbol pairs that they yield. It
+// returns the number of bytes written to dst and read from src, and the new bit
+// queue.
+func (enc *Encoding) encodeWords(dst, src []byte, queue, numBits uint32) (n, i int, q, nb uint32) {
+	// The queue holds at most 13 bits between words, so adding 32 bits at a time
+	// fits in 64 bits.
+	acc := uint64(queue)
+
+	// s and d are the parts of src and dst not yet used. Each word is encoded
+	// into a fixed-size window of d, indexed with masked offsets, so that the
+	// loop needs no bounds checks.
+	s, d := src, dst
+	for len(s) >= 8 && len(d) >= 16 {
+		w := binary.LittleEndian.Uint64(s)
+		out := (*[16]byte)(d)
+		k := 0
+		for half := 0; half < 2; half++ {
+			acc |= (w & 0xffffffff) << numBits
+			w >>= 32
+			numBits += 32
+
+			for numBits > 13 {
+				v := uint32(acc) & 8191
+				if v > 88 {
+					acc >>= 13
+					numBits -= 13
+				} else {
+					v = uint32(acc) & 16383
+					acc >>= 14
+					numBits -= 14
+				}
+				p := enc.pair(v)
+				out[k&15] = byte(p)
+				out[(k+1)&15] = byte(p >> 8)
+				k += 2
+			}
+		}
+		s = s[8:]
+		d = d[k&15:]
+	}
+
+	return len(dst) - len(d), len(src) - len(s), uint32(acc), numBits
+}
+
+// countWords is like encodeWords but only counts the symbol pairs that src
+// encodes to, for EncodedLenExact. It returns the number of pairs and of bytes
+// read from src, and the new bit queue.
+func countWords(src []byte, queue, numBits uint32) (pairs, i int, q, nb uint32) {
+	acc := uint64(queue)
+	s := src
+	for ; len(s) >= 8; s = s[8:] {
+		w := binary.LittleEndian.Uint64(s)
+		for half := 0; half < 2; half++ {
+			acc |= (w & 0xffffffff) << numBits
+			w >>= 32
+			numBits += 32
+
+			for numBits > 13 {
+				if acc&8191 > 88 {
+					acc >>= 13
+					numBits -= 13
+				} else {
+					acc >>= 14
+					numBits -= 14
+				}
+				pairs++
+			}
+		}
+	}
+
+	return pairs, len(src) - len(s), uint32(acc), numBits
+}
+
+// decodeWords decodes src to dst 8 bytes, or 4 symbol pairs, at a time,
+// starting from the given bit queue, for as long as the next 8 bytes of src
+// are all symbols and dst has room for a whole 8-byte word. It returns the
+// number of bytes written to dst and read from src, the new bit queue, and the
+// value and preceding leftover bit count of the last pair that it decoded.
+//
+// decodeWords writes whole words, so it may write to dst beyond the bytes it
+// reports. Since each word is written at an offset in dst no greater than the
+// offset in src of the word just read, it never writes past the end of the
+// input read so far, which keeps decoding in place safe.
+func (enc *Encoding) decodeWords(dst, src []byte, queue, numBits uint32) (n, i int, q, nb, lastV, lastNumBits uint32) {
+	// The queue holds at most 7 bits between words, so adding 4 pairs of at
+	// most 14 bits each fits in 64 bits.
+	acc := uint64(queue)
+	pairs := enc.decodePairs
+
+	// d is the part of dst not yet written, sliced as the loop goes so that
+	// writing to it needs no bounds check.
+	var vs [4]uint32
+	d := dst
+	for ; i+8 <= len(src) && len(d) >= 8; i += 8 {
+		s := src[i : i+8 : i+8]
+		if pairs != nil {
+			p0 := pairs[int(s[0])|int(s[1])<<8]
+			p1 := pairs[int(s[2])|int(s[3])<<8]
+			p2 := pairs[int(s[4])|int(s[5])<<8]
+			p3 := pairs[int(s[6])|int(s[7])<<8]
+			// Pair values are below 91*91, and the marker for anything else has
+			// the high bit set, so one test checks all four.
+			if (p0|p1|p2|p3)&0x8000 != 0 {
+				break
+			}
+			vs = [4]uint32{uint32(p0), uint32(p1), uint32(p2), uint32(p3)}
+		} else {
+			m := &enc.decodeMap
+			d0, d1, d2, d3 := m[s[0]], m[s[1]], m[s[2]], m[s[3]]
+			d4, d5, d6, d7 := m[s[4]], m[s[5]], m[s[6]], m[s[7]]
+			// Symbol values are below 91, and the markers for other bytes have
+			// the high bit set, so one test checks all eight.
+			if (d0|d1|d2|d3|d4|d5|d6|d7)&0x80 != 0 {
+				break
+			}
+			vs = [4]uint32{
+				uint32(d0) + uint32(d1)*91,
+				uint32(d2) + uint32(d3)*91,
+				uint32(d4) + uint32(d5)*91,
+				uint32(d6) + uint32(d7)*91,
+			}
+		}
+
+		for _, v := range vs {
+			lastV, lastNumBits = v, numBits
+			acc |= uint64(v) << numBits
+			numBits += groupBits(v)
+		}
+
+		// The queue holds at most 63 bits, so k is at most 7 and the mask only
+		// tells the compiler so.
+		binary.LittleEndian.PutUint64(d, acc)
+		k := numBits / 8
+		d = d[k&7:]
+		acc >>= 8 * k
+		numBits -= 8 * k
+	}
+
+	return len(dst) - len(d), i, uint32(acc), numBits, lastV, lastNumBits
+}
+
+// skipValidWords returns the length of the longest prefix of src, a multiple
+// of 8 bytes long, in which no byte maps to 0xff in decodeMap.
+func skipValidWords(decodeMap *[256]byte, src []byte) int {
+	i := 0
+	for ; i+8 <= len(src); i += 8 {
+		s := src[i : i+8 : i+8]
+		w := uint64(decodeMap[s[0]]) | uint64(decodeMap[s[1]])<<8 |
+			uint64(decodeMap[s[2]])<<16 | uint64(decodeMap[s[3]])<<24 |
+			uint64(decodeMap[s[4]])<<32 | uint64(decodeMap[s[5]])<<40 |
+			uint64(decodeMap[s[6]])<<48 | uint64(decodeMap[s[7]])<<56
+		// Only the markers 0xfe and 0xff have the high bit set, and of those
+		// only 0xff has the low bit set too. Shifting right by 7 lines each
+		// byte's high bit up with its low bit, so this tests all eight at once.
+		if w&(w>>7)&0x0101010101010101 != 0 {
+			break
+		}
+	}
+	return i
+}
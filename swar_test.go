@@ -0,0 +1,139 @@
+package base91
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// encodeBytewise is a reference encoder that takes one byte at a time, against
+// which the word-at-a-time path is checked.
+func encodeBytewise(src []byte) string {
+	var dst []byte
+	var queue, numBits uint32
+	for _, c := range src {
+		queue |= uint32(c) << numBits
+		numBits += 8
+		if numBits > 13 {
+			v := queue & 8191
+			if v > 88 {
+				queue >>= 13
+				numBits -= 13
+			} else {
+				v = queue & 16383
+				queue >>= 14
+				numBits -= 14
+			}
+			dst = append(dst, encodeStd[v%91], encodeStd[v/91])
+		}
+	}
+	if numBits > 0 {
+		dst = append(dst, encodeStd[queue%91])
+		if numBits > 7 || queue > 90 {
+			dst = append(dst, encodeStd[queue/91])
+		}
+	}
+	return string(dst)
+}
+
+func TestEncodeWords(t *testing.T) {
+	src := benchmarkData(300)
+	for _, enc := range []*Encoding{StdEncoding, NewEncodingWithOptions(encodeStd, EncodeTable())} {
+		for n := 0; n <= len(src); n++ {
+			want := encodeBytewise(src[:n])
+			if got := enc.EncodeToString(src[:n]); got != want {
+				t.Fatalf("%v: EncodeToString of %d bytes: expected %q, got %q", enc, n, want, got)
+			}
+
+			// Every prefix of the output must be written when dst is short.
+			dst := make([]byte, len(want)/2)
+			k, err := enc.Encode(dst, src[:n])
+			if len(want) > 0 && err != ErrShortDst {
+				t.Fatalf("%v: Encode of %d bytes into %d: expected %v, got %v", enc, n, len(dst), ErrShortDst, err)
+			}
+			if got := string(dst[:k]); got != want[:k] || k < len(dst)-1 {
+				t.Fatalf("%v: Encode of %d bytes into %d: got %q, a bad prefix of %q", enc, n, len(dst), got, want)
+			}
+		}
+	}
+}
+
+func TestDecodeWords(t *testing.T) {
+	data := benchmarkData(300)
+	encs := []*Encoding{
+		StdEncoding,
+		NewEncodingWithOptions(encodeStd, DecodeTable()),
+		NewEncodingWithOptions(encodeStd, Strict()),
+	}
+	for _, enc := range encs {
+		for n := 0; n <= len(data); n++ {
+			encoded := StdEncoding.EncodeToString(data[:n])
+
+			got, err := enc.DecodeString(encoded)
+			if err != nil {
+				t.Fatalf("%v: Got decoding error for %d bytes: %v", enc, n, err)
+			}
+			if !bytes.Equal(got, data[:n]) {
+				t.Fatalf("%v: Expected %x, got %x", enc, data[:n], got)
+			}
+
+			buf := []byte(encoded)
+			k, err := enc.Decode(buf, buf)
+			if err != nil || !bytes.Equal(buf[:k], data[:n]) {
+				t.Fatalf("%v: Decoding %d bytes in place: expected %x, got %x (error: %v)", enc, n, data[:n], buf[:k], err)
+			}
+		}
+	}
+}
+
+func TestDecodeWordsFallback(t *testing.T) {
+	data := benchmarkData(64)
+	encoded := StdEncoding.EncodeToString(data)
+
+	for _, enc := range []*Encoding{StdEncoding, NewEncodingWithOptions(encodeStd, DecodeTable())} {
+		// A byte that is not a symbol anywhere in the input must be handled by
+		// the byte-at-a-time loop, whether it is skipped or rejected.
+		for i := 0; i <= len(encoded); i++ {
+			t.Run(fmt.Sprintf("%v/%d", enc, i), func(t *testing.T) {
+				broken := encoded[:i] + "\n" + encoded[i:]
+				got, err := enc.DecodeString(broken)
+				if err != nil || !bytes.Equal(got, data) {
+					t.Errorf("Expected %x, got %x (error: %v)", data, got, err)
+				}
+
+				broken = encoded[:i] + "-" + encoded[i:]
+				_, err = enc.DecodeString(broken)
+				if e, ok := err.(CorruptInputError); !ok || e.Offset != int64(i) {
+					t.Errorf("Expected error at offset %d, got %v", i, err)
+				}
+			})
+		}
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	src := benchmarkData(300)
+	for n := 0; n <= len(src); n++ {
+		if got, want := StdEncoding.EncodedLenExact(src[:n]), len(encodeBytewise(src[:n])); got != want {
+			t.Fatalf("EncodedLenExact of %d bytes: expected %d, got %d", n, want, got)
+		}
+	}
+}
+
+func TestSkipValidWords(t *testing.T) {
+	encoded := StdEncoding.EncodeToString(benchmarkData(64))
+	if got := StdEncoding.IndexInvalid([]byte(encoded + "\r\n" + encoded)); got != -1 {
+		t.Errorf("Expected -1, got %d", got)
+	}
+	for i := 0; i <= len(encoded); i++ {
+		for _, c := range []string{"-", " ", "\x00", "\xff"} {
+			broken := encoded[:i] + c + encoded[i:]
+			if got := StdEncoding.IndexInvalid([]byte(broken)); got != i {
+				t.Fatalf("Expected %d for %q at %d, got %d", i, c, i, got)
+			}
+			if StdEncoding.ValidString(broken) {
+				t.Fatalf("Expected %q at %d to be invalid", c, i)
+			}
+		}
+	}
+}
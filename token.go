@@ -0,0 +1,34 @@
+package base91
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+)
+
+// NewToken returns the encoding, never wrapped, of nBytes bytes read from
+// crypto/rand, for use as a secret such as an API key or CSRF token. 16 bytes
+// is enough for most purposes, and 32 for long-lived secrets. The encoding
+// takes about 23% more characters than nBytes. Pick enc for where the token
+// will be used: JSONEncoding for JSON documents, say, or URLTolerantEncoding
+// for query strings. NewToken panics if nBytes is not positive, and returns an
+// error only if crypto/rand fails.
+//
+// Compare a token presented by a client with the one issued using EqualToken,
+// not ==, which takes time that depends on where they first differ.
+func (enc *Encoding) NewToken(nBytes int) (string, error) {
+	if nBytes <= 0 {
+		panic("token size must be positive")
+	}
+	b := make([]byte, nBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return string(enc.appendUnwrapped(nil, b)), nil
+}
+
+// EqualToken reports whether token and want are equal, in time that depends on
+// their lengths but not on their contents, so that checking a guessed token
+// against a secret one reveals nothing about the secret beyond its length.
+func EqualToken(token, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
@@ -0,0 +1,54 @@
+package base91
+
+import "testing"
+
+func TestNewToken(t *testing.T) {
+	for _, n := range []int{1, 16, 32} {
+		a, err := URLTolerantEncoding.NewToken(n)
+		if err != nil {
+			t.Fatalf("Got error: %v", err)
+		}
+		b, err := URLTolerantEncoding.NewToken(n)
+		if err != nil {
+			t.Fatalf("Got error: %v", err)
+		}
+
+		data, err := URLTolerantEncoding.DecodeString(a)
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if len(data) != n {
+			t.Errorf("Expected %d bytes, got %d", n, len(data))
+		}
+		if n >= 16 && a == b {
+			t.Errorf("Expected distinct tokens, got %q twice", a)
+		}
+	}
+}
+
+func TestNewTokenPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic, got none")
+		}
+	}()
+	StdEncoding.NewToken(0)
+}
+
+func TestEqualToken(t *testing.T) {
+	cases := []struct {
+		token, want string
+		equal       bool
+	}{
+		{"abc", "abc", true},
+		{"abc", "abd", false},
+		{"ab", "abc", false},
+		{"", "abc", false},
+	}
+
+	for _, tc := range cases {
+		if got := EqualToken(tc.token, tc.want); got != tc.equal {
+			t.Errorf("EqualToken(%q, %q): expected %v, got %v", tc.token, tc.want, tc.equal, got)
+		}
+	}
+}
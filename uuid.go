@@ -0,0 +1,30 @@
+package base91
+
+// EncodeUUID returns the base91 encoding of the UUID u, never wrapped. All but
+// fewer than one in 500,000 UUIDs encode to 20 characters, and the rest to 19,
+// against 36 for the usual hexadecimal form and 22 for unpadded base64.
+func (enc *Encoding) EncodeUUID(u [16]byte) string {
+	dst, n := enc.Encode16(u)
+	return string(dst[:n])
+}
+
+// DecodeUUID returns the UUID encoded in s. So that each UUID has exactly one
+// valid string, which matters for IDs that are compared or indexed as strings,
+// s must be exactly what EncodeUUID produces: DecodeUUID returns
+// ErrWrongLength if s does not decode to 16 bytes, and a CorruptInputError if
+// it contains anything outside the alphabet, including line breaks, or is
+// otherwise not the canonical encoding of its UUID.
+func (enc *Encoding) DecodeUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	if _, err := enc.decodeCanonical(u[:], stringBytes(s), true); err != nil {
+		return [16]byte{}, err
+	}
+	return u, nil
+}
+
+// ValidUUID reports whether s is the encoding of a UUID, as accepted by
+// DecodeUUID.
+func (enc *Encoding) ValidUUID(s string) bool {
+	_, err := enc.DecodeUUID(s)
+	return err == nil
+}
@@ -0,0 +1,71 @@
+package base91
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestUUID(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		var u [16]byte
+		rng.Read(u[:])
+		s := StdEncoding.EncodeUUID(u)
+		if len(s) != 20 {
+			t.Errorf("%x: expected 20 characters, got %q", u, s)
+		}
+		got, err := StdEncoding.DecodeUUID(s)
+		if err != nil {
+			t.Fatalf("%x: got decoding error: %v", u, err)
+		}
+		if got != u {
+			t.Errorf("Expected %x, got %x", u, got)
+		}
+	}
+
+	// Zero bytes encode to 14-bit groups, so the nil UUID is shorter.
+	if s := StdEncoding.EncodeUUID([16]byte{}); s != "AAAAAAAAAAAAAAAAAAA" {
+		t.Errorf("Expected %q, got %q", "AAAAAAAAAAAAAAAAAAA", s)
+	}
+}
+
+func TestDecodeUUIDInvalid(t *testing.T) {
+	var u [16]byte
+	copy(u[:], "0123456789abcdef")
+	s := StdEncoding.EncodeUUID(u)
+
+	cases := []struct {
+		s      string
+		offset int64 // Offset of a CorruptInputError, or -1 for ErrWrongLength.
+	}{
+		{s[:10] + "\n" + s[10:], 10},
+		{s[:19] + "~", 18},
+		{s[:18], -1},
+		{s + "AA", -1},
+		{"", -1},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			_, err := StdEncoding.DecodeUUID(tc.s)
+			if StdEncoding.ValidUUID(tc.s) {
+				t.Errorf("Expected %q to be invalid", tc.s)
+			}
+			if tc.offset < 0 {
+				if err != ErrWrongLength {
+					t.Errorf("Expected ErrWrongLength, got %v", err)
+				}
+				return
+			}
+			var e CorruptInputError
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected CorruptInputError, got %v", err)
+			}
+			if e.Offset != tc.offset {
+				t.Errorf("Expected offset %d, got %d", tc.offset, e.Offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package base91
+
+// The encodings in this file are variants of StdEncoding for embedding encoded
+// data in particular kinds of text without escaping. Each replaces the
+// characters of the standard alphabet that would need escaping with some of
+// the four printable characters that the standard alphabet leaves out, so that
+// the output is exactly as long as that of StdEncoding. Where it can, a variant
+// replaces '"' with an apostrophe.
+
+// JSONEncoding is the standard encoding with '"' replaced by an apostrophe.
+// Its output can be placed in a JSON string as it is, without the escaping
+// that '"' would need, so it is no longer than the output of StdEncoding.
+var JSONEncoding = mustClone(StdEncoding, map[byte]byte{'"': '\''})
+
+// XMLEncoding is the standard encoding with '"', '<', '>', and '&' replaced by
+// an apostrophe, '-', '\\', and a space. Its output can be placed in XML or
+// HTML text, or in an attribute value in double quotes, without entities,
+// which would take 4 to 6 bytes for each of those characters. Since its output
+// may start or end with a space, it must be placed where whitespace is kept as
+// it is; XML processors that trim or collapse whitespace in text would change
+// it.
+var XMLEncoding = mustClone(StdEncoding, map[byte]byte{'"': '\'', '<': '-', '>': '\\', '&': ' '})
+
+// CSVEncoding is the standard encoding with '"' and ',' replaced by an
+// apostrophe and '-'. Its output can be written as an unquoted CSV field.
+// Spreadsheet programs may treat a field that starts with '=', '+', '-', or '@'
+// as a formula, so output that is to be opened in one should be prefixed or
+// placed where formulas are not evaluated.
+var CSVEncoding = mustClone(StdEncoding, map[byte]byte{'"': '\'', ',': '-'})
+
+// ShellEncoding is the standard encoding with '"', '$', and '`' replaced by an
+// apostrophe, '-', and a space. Its output can be placed in a double-quoted
+// POSIX shell string, such as in a generated script or CI configuration,
+// without escaping. Interactive shells with history expansion, such as bash,
+// also treat '!' as special in double quotes, but scripts are unaffected.
+var ShellEncoding = mustClone(StdEncoding, map[byte]byte{'"': '\'', '$': '-', '`': ' '})
+
+// QuoteSafeEncoding is the standard encoding with '"' replaced by '-'. Since
+// the standard alphabet already leaves out the apostrophe and backslash, its
+// output contains no quote characters or backslashes, and can be placed as it
+// is in a string literal quoted with either kind of quote in most languages
+// and configuration formats.
+var QuoteSafeEncoding = mustClone(StdEncoding, map[byte]byte{'"': '-'})
+
+// URLTolerantEncoding is the standard encoding with '#' and '%' replaced by '-'
+// and an apostrophe. Even when its output ends up in a URL without being
+// escaped, it is neither cut short at a '#', which starts the fragment, nor
+// changed by percent-decoding. It still contains 14 characters that must be
+// escaped in a query value, as EncodeQueryParam does.
+var URLTolerantEncoding = mustClone(StdEncoding, map[byte]byte{'#': '-', '%': '\''})
+
+// mustClone is like Clone but panics if the replacements are invalid.
+func mustClone(enc *Encoding, replacements map[byte]byte) *Encoding {
+	e, err := enc.Clone(replacements)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
@@ -0,0 +1,168 @@
+package base91
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestVariantsSafe(t *testing.T) {
+	cases := []struct {
+		enc *Encoding
+		ctx Context
+	}{
+		{JSONEncoding, JSONString},
+		{XMLEncoding, XMLText},
+		{CSVEncoding, CSVField},
+		{ShellEncoding, ShellDoubleQuoted},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if got := CheckAlphabetSafeFor(tc.enc, tc.ctx); got != "" {
+				t.Errorf("%v: expected no unsafe characters, got %q", tc.ctx, got)
+			}
+		})
+	}
+}
+
+func TestVariantsOmit(t *testing.T) {
+	cases := []struct {
+		enc     *Encoding
+		omitted string
+	}{
+		{QuoteSafeEncoding, "\"'\\"},
+		{URLTolerantEncoding, "#%"},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if alphabet := string(tc.enc.encode[:]); strings.ContainsAny(alphabet, tc.omitted) {
+				t.Errorf("Expected alphabet without any of %q, got %q", tc.omitted, alphabet)
+			}
+		})
+	}
+
+	if got := CheckAlphabetSafeFor(URLTolerantEncoding, URLQuery); len(got) != 14 {
+		t.Errorf("Expected 14 characters to escape in a query, got %q", got)
+	}
+}
+
+func TestVariantsDistinct(t *testing.T) {
+	encodings := []*Encoding{StdEncoding, JSONEncoding, XMLEncoding, CSVEncoding, ShellEncoding, QuoteSafeEncoding, URLTolerantEncoding}
+	seen := make(map[string]int)
+	for i, enc := range encodings {
+		if j, ok := seen[enc.alphabetID()]; ok {
+			t.Errorf("Expected distinct alphabets, got the same for encodings %d and %d", j, i)
+		}
+		seen[enc.alphabetID()] = i
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	data := benchmarkData(1000)
+	s := JSONEncoding.EncodeToString(data)
+
+	// Marshaling adds only the quotes and a newline. By default encoding/json
+	// also escapes '<', '>', and '&' for HTML, which JSON itself does not need.
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(s); err != nil {
+		t.Fatalf("Got marshaling error: %v", err)
+	}
+	if buf.Len() != len(s)+3 {
+		t.Errorf("Expected %d bytes of JSON, got %d", len(s)+3, buf.Len())
+	}
+
+	decoded, err := JSONEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Got decoding error: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("Expected %x, got %x", data, decoded)
+	}
+}
+
+func TestXMLEncoding(t *testing.T) {
+	type doc struct {
+		Attr string `xml:"attr,attr"`
+		Text string `xml:",chardata"`
+	}
+
+	data := benchmarkData(1000)
+	s := XMLEncoding.EncodeToString(data)
+
+	// The output can be placed in the document as it is. (xml.Marshal would
+	// still escape apostrophes, though they need no escaping.)
+	x := []byte(`<doc attr="` + s + `">` + s + `</doc>`)
+
+	var d doc
+	if err := xml.Unmarshal(x, &d); err != nil {
+		t.Fatalf("Got unmarshaling error: %v", err)
+	}
+	for _, v := range []string{d.Attr, d.Text} {
+		decoded, err := XMLEncoding.DecodeString(v)
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("Expected %x, got %x", data, decoded)
+		}
+	}
+}
+
+func TestCSVEncoding(t *testing.T) {
+	var records [][]string
+	var data [][]byte
+	for i := 0; i < 20; i++ {
+		d := benchmarkData(50 * i)
+		data = append(data, d)
+		records = append(records, []string{"x", CSVEncoding.EncodeToString(d)})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		t.Fatalf("Got CSV writing error: %v", err)
+	}
+	if bytes.ContainsRune(buf.Bytes(), '"') {
+		t.Errorf("Expected no quoted fields, got %q", buf.String())
+	}
+
+	got, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Got CSV reading error: %v", err)
+	}
+	for i, r := range got {
+		decoded, err := CSVEncoding.DecodeString(r[1])
+		if err != nil {
+			t.Fatalf("Got decoding error: %v", err)
+		}
+		if !bytes.Equal(decoded, data[i]) {
+			t.Errorf("Expected %x, got %x", data[i], decoded)
+		}
+	}
+}
+
+func TestShellEncoding(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available")
+	}
+
+	data := benchmarkData(1000)
+	s := ShellEncoding.EncodeToString(data)
+	out, err := exec.Command(sh, "-c", `printf '%s' "`+s+`"`).Output()
+	if err != nil {
+		t.Fatalf("Got shell error: %v", err)
+	}
+	if string(out) != s {
+		t.Errorf("Expected %q, got %q", s, out)
+	}
+}
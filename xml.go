@@ -0,0 +1,31 @@
+package base91
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// MarshalXML implements xml.Marshaler, writing b as an element whose character
+// data is the base91 encoding of b in the standard alphabet. The encoder writes
+// the symbols '<', '>', '&', and '"' as entities, so the output is always
+// well-formed; XMLEncoding avoids them, but Bytes keeps to the standard
+// alphabet so that any base91 decoder can read the data.
+func (b Bytes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(StdEncoding.EncodeToString(b), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler, decoding the character data of the
+// element into *b. Whitespace around the data, as left by indentation, is
+// ignored.
+func (b *Bytes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	decoded, err := StdEncoding.DecodeString(strings.Trim(s, " \t\r\n"))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
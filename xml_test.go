@@ -0,0 +1,54 @@
+package base91
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBytesXML(t *testing.T) {
+	type doc struct {
+		XMLName xml.Name `xml:"doc"`
+		Payload Bytes    `xml:"payload"`
+	}
+
+	// Find data whose encoding includes every symbol that XML escapes.
+	var data []byte
+	for n := 1; data == nil; n++ {
+		data = benchmarkData(n)
+		s := StdEncoding.EncodeToString(data)
+		for _, c := range "<>&\"" {
+			if !strings.ContainsRune(s, c) {
+				data = nil
+			}
+		}
+	}
+
+	b, err := xml.MarshalIndent(doc{Payload: data}, "", "  ")
+	if err != nil {
+		t.Fatalf("Got marshaling error: %v", err)
+	}
+	var d doc
+	if err := xml.Unmarshal(b, &d); err != nil {
+		t.Fatalf("Got unmarshaling error: %v", err)
+	}
+	if !bytes.Equal(d.Payload, data) {
+		t.Errorf("Expected %x, got %x", data, []byte(d.Payload))
+	}
+
+	// Indentation around the data is ignored.
+	s := StdEncoding.EncodeToString([]byte("foobar"))
+	if err := xml.Unmarshal([]byte("<doc><payload>\n    "+s+"\n  </payload></doc>"), &d); err != nil {
+		t.Fatalf("Got unmarshaling error: %v", err)
+	}
+	if string(d.Payload) != "foobar" {
+		t.Errorf("Expected %q, got %q", "foobar", []byte(d.Payload))
+	}
+
+	err = xml.Unmarshal([]byte("<doc><payload>ab c</payload></doc>"), &d)
+	if !errors.Is(err, ErrCorruptInput) {
+		t.Errorf("Expected ErrCorruptInput, got %v", err)
+	}
+}